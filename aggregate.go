@@ -0,0 +1,91 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+// aggregationRule sums up the series of a metric family whose label value
+// matches Regex into a series with the label value Target. Target may refer
+// to capture groups of Regex, e.g. "$1". DropLabels names further labels
+// whose values differ between the summed up series, e.g. the action_index of
+// numbered actions, which the aggregated series does not carry.
+type aggregationRule struct {
+	Family        string   `json:"family"`
+	Regex         string   `json:"regex"`
+	Target        string   `json:"target"`
+	DropLabels    []string `json:"drop_labels,omitempty"`
+	DropOriginals bool     `json:"drop_originals,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+func (r *aggregationRule) validate() error {
+	if r.Family == "" {
+		return errors.New("family must be set")
+	}
+	if r.Target == "" {
+		return errors.New("target must be set")
+	}
+	for _, name := range r.DropLabels {
+		if err := validateLabelName(name); err != nil {
+			return err
+		}
+	}
+	re, err := regexp.Compile("^(?:" + r.Regex + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid regex: %v", err)
+	}
+	r.regex = re
+	return nil
+}
+
+// target returns the label value of the aggregated series p is summed up
+// into, or false if the rule does not apply to p. Summaries and ratios can
+// not be summed up and are never aggregated.
+func (r *aggregationRule) target(p *impstats.Point) (string, bool) {
+	if p.Name != r.Family || p.Type == impstats.Summary || p.Divisor != 0 {
+		return "", false
+	}
+	indexes := r.regex.FindStringSubmatchIndex(p.LabelValue)
+	if indexes == nil {
+		return "", false
+	}
+	return string(r.regex.ExpandString([]byte{}, r.Target, p.LabelValue, indexes)), true
+}
+
+// aggregate returns the first rule applying to p and the aggregated point p
+// contributes to.
+func aggregate(rules []*aggregationRule, p *impstats.Point) (*aggregationRule, *impstats.Point) {
+	for _, r := range rules {
+		if target, ok := r.target(p); ok {
+			a := *p
+			a.LabelValue = target
+			a.ExtraLabels = nil
+			for _, l := range p.ExtraLabels {
+				if !slices.Contains(r.DropLabels, l.Name) {
+					a.ExtraLabels = append(a.ExtraLabels, l)
+				}
+			}
+			return r, &a
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestAggregationRuleTarget(t *testing.T) {
+	r := &aggregationRule{Family: "action_processed", Regex: `action-\d+-(.*)`, Target: "all-$1"}
+	if err := r.validate(); err != nil {
+		t.Fatalf("expected rule to be valid, got: %v", err)
+	}
+
+	testCases := []struct {
+		p      *impstats.Point
+		target string
+		ok     bool
+	}{
+		{&impstats.Point{Name: "action_processed", Type: impstats.Counter, LabelValue: "action-3-builtin:omfile"}, "all-builtin:omfile", true},
+		{&impstats.Point{Name: "action_processed", Type: impstats.Counter, LabelValue: "to_exporter"}, "", false},
+		{&impstats.Point{Name: "action_failed", Type: impstats.Counter, LabelValue: "action-3-builtin:omfile"}, "", false},
+		{&impstats.Point{Name: "action_processed", Type: impstats.Summary, LabelValue: "action-3-builtin:omfile"}, "", false},
+		{&impstats.Point{Name: "action_processed", Type: impstats.Gauge, Divisor: 2, LabelValue: "action-3-builtin:omfile"}, "", false},
+	}
+
+	for _, tc := range testCases {
+		target, ok := r.target(tc.p)
+		if target != tc.target || ok != tc.ok {
+			t.Errorf("%s %s: want (%s, %t), got (%s, %t)", tc.p.Name, tc.p.LabelValue, tc.target, tc.ok, target, ok)
+		}
+	}
+}
+
+func TestHandleLineWithAggregation(t *testing.T) {
+	cfg, err := parseConfig([]byte(`{
+		"aggregations": [
+			{"family": "input_received", "regex": "imudp\\(w\\d+\\)", "target": "all", "drop_originals": true},
+			{"family": "input_called_recvmmsg", "regex": "imudp\\(w\\d+\\)", "target": "all"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("expected parsing config not to fail, got: %v", err)
+	}
+
+	re := newRsyslogExporter()
+	re.aggregationRules = cfg.Aggregations
+
+	lines := []string{
+		`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"imudp(w0)","origin":"imudp","called.recvmmsg":10,"called.recvmsg":0,"msgs.received":100}`,
+		`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"imudp(w1)","origin":"imudp","called.recvmmsg":20,"called.recvmsg":0,"msgs.received":200}`,
+		`2017-08-30T08:11:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"imudp(w0)","origin":"imudp","called.recvmmsg":15,"called.recvmsg":0,"msgs.received":150}`,
+	}
+	for _, line := range lines {
+		if err := re.handleStatLine([]byte(line)); err != nil {
+			t.Fatalf("expected handling line not to fail, got: %v", err)
+		}
+	}
+
+	p, err := re.get(`input_received{worker="all"}`)
+	if err != nil {
+		t.Fatalf("expected aggregated series to exist, got: %v", err)
+	}
+	if want, got := int64(350), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+	if _, err := re.get(`input_received{worker="imudp(w0)"}`); err != errPointNotFound {
+		t.Error("expected original series to be dropped")
+	}
+
+	p, err = re.get(`input_called_recvmmsg{worker="all"}`)
+	if err != nil {
+		t.Fatalf("expected aggregated series to exist, got: %v", err)
+	}
+	if want, got := int64(35), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+	if _, err := re.get(`input_called_recvmmsg{worker="imudp(w0)"}`); err != nil {
+		t.Errorf("expected original series to be kept, got: %v", err)
+	}
+
+	re.delete(`input_received{worker="imudp(w1)"}`)
+	p, err = re.get(`input_received{worker="all"}`)
+	if err != nil {
+		t.Fatalf("expected aggregated series to exist, got: %v", err)
+	}
+	if want, got := int64(150), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
+
+func TestHandleLineWithActionAggregation(t *testing.T) {
+	cfg, err := parseConfig([]byte(`{
+		"aggregations": [
+			{"family": "action_processed", "regex": "action \\d+", "target": "numbered", "drop_labels": ["action_index", "module", "builtin"], "drop_originals": true},
+			{"family": "action_failed", "regex": "action-\\d+-(.*)", "target": "unnamed-$1", "drop_labels": ["action_index"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("expected parsing config not to fail, got: %v", err)
+	}
+
+	re := newRsyslogExporter()
+	re.aggregationRules = cfg.Aggregations
+
+	for i, name := range []string{"action 0", "action 1", "action 2", "action-3-builtin:omfile", "action-4-builtin:omfile"} {
+		line := fmt.Sprintf(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"%s","origin":"core.action","processed":%d,"failed":%d,"suspended":0,"suspended.duration":0,"resumed":0}`, name, 10*(i+1), i+1)
+		if err := re.handleStatLine([]byte(line)); err != nil {
+			t.Fatalf("expected handling line not to fail, got: %v", err)
+		}
+	}
+
+	var processed, failed []*impstats.Point
+	for _, k := range re.keys() {
+		p, err := re.get(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch p.Name {
+		case "action_processed":
+			processed = append(processed, p)
+		case "action_failed":
+			if p.LabelValue == "unnamed-builtin:omfile" {
+				failed = append(failed, p)
+			}
+		}
+	}
+
+	// The numbered actions are summed up into a single series without the
+	// labels differing between them, next to the named actions.
+	var numbered *impstats.Point
+	for _, p := range processed {
+		if p.LabelValue == "numbered" {
+			if numbered != nil {
+				t.Fatalf("want a single aggregated series, got %v and %v", numbered.Labels(), p.Labels())
+			}
+			numbered = p
+		}
+	}
+	if numbered == nil {
+		t.Fatal("expected aggregated series to exist")
+	}
+	if want, got := int64(60), numbered.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+	if want, got := 0, len(numbered.ExtraLabels); want != got {
+		t.Errorf("want '%d' extra labels, got %v", want, numbered.ExtraLabels)
+	}
+	if want, got := 3, len(processed); want != got {
+		t.Errorf("want '%d' action_processed series, got '%d'", want, got)
+	}
+
+	if want, got := 1, len(failed); want != got {
+		t.Fatalf("want '%d' aggregated series, got '%d'", want, got)
+	}
+	if want, got := int64(9), failed[0].Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+	if want, got := "[{action unnamed-builtin:omfile} {module omfile} {builtin true}]", fmt.Sprint(failed[0].Labels()); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(re)
+	if _, err := reg.Gather(); err != nil {
+		t.Errorf("expected gathering aggregated series not to fail, got: %v", err)
+	}
+}
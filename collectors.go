@@ -0,0 +1,72 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+// collectorFlags holds the --collector.<name> and --no-collector.<name>
+// switches of an impstats type.
+type collectorFlags struct {
+	enable  *bool
+	disable *bool
+}
+
+// collectorNames holds the collector names of impstats types not named after
+// the type. The type names are kept as aliases.
+var collectorNames = map[impstats.Type]string{
+	impstats.TypeDynStat: "dynstats",
+}
+
+// collectorName returns the name of the collector of an impstats type.
+func collectorName(t impstats.Type) string {
+	if name, ok := collectorNames[t]; ok {
+		return name
+	}
+	return t.String()
+}
+
+// registerCollectorFlags registers the switches enabling and disabling the
+// collection of each impstats type.
+func registerCollectorFlags(fs *flag.FlagSet) map[impstats.Type]collectorFlags {
+	flags := make(map[impstats.Type]collectorFlags)
+	for _, t := range impstats.Types() {
+		name := collectorName(t)
+		f := collectorFlags{
+			enable:  fs.Bool("collector."+name, true, fmt.Sprintf("Enable the %s collector", name)),
+			disable: fs.Bool("no-collector."+name, false, fmt.Sprintf("Disable the %s collector", name)),
+		}
+		if alias := t.String(); alias != name {
+			fs.BoolVar(f.enable, "collector."+alias, true, fmt.Sprintf("Alias of --collector.%s", name))
+			fs.BoolVar(f.disable, "no-collector."+alias, false, fmt.Sprintf("Alias of --no-collector.%s", name))
+		}
+		flags[t] = f
+	}
+	return flags
+}
+
+// disabledCollectors returns the impstats types disabled by the switches.
+func disabledCollectors(flags map[impstats.Type]collectorFlags) map[impstats.Type]bool {
+	disabled := make(map[impstats.Type]bool)
+	for t, f := range flags {
+		if !*f.enable || *f.disable {
+			disabled[t] = true
+		}
+	}
+	return disabled
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+func TestCollectorFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerCollectorFlags(fs)
+	if err := fs.Parse([]string{"--no-collector.queue", "--collector.dynstats=false", "--no-collector.dynafile_cache"}); err != nil {
+		t.Fatal(err)
+	}
+
+	disabled := disabledCollectors(flags)
+	if want, got := 3, len(disabled); want != got {
+		t.Errorf("want %d disabled collectors, got %d", want, got)
+	}
+	if !disabled[impstats.TypeQueue] || !disabled[impstats.TypeDynStat] || !disabled[impstats.TypeDynafileCache] {
+		t.Errorf("expected queue, dynstats and dynafile_cache collectors to be disabled, got %v", disabled)
+	}
+}
+
+func TestCollectorFlagAliases(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerCollectorFlags(fs)
+	if err := fs.Parse([]string{"--no-collector.dynstat"}); err != nil {
+		t.Fatal(err)
+	}
+
+	disabled := disabledCollectors(flags)
+	if want, got := 1, len(disabled); want != got {
+		t.Errorf("want %d disabled collectors, got %d", want, got)
+	}
+	if !disabled[impstats.TypeDynStat] {
+		t.Errorf("expected dynstats collector to be disabled by its alias, got %v", disabled)
+	}
+}
+
+func TestHandleLineWithDisabledCollector(t *testing.T) {
+	re := newRsyslogExporter()
+	re.disabledCollectors = map[impstats.Type]bool{impstats.TypeQueue: true}
+
+	line := `2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"main Q","origin":"core.queue","size":10,"enqueued":20,"full":30,"discarded.full":40,"discarded.nf":50,"maxqsize":60}`
+	if err := re.handleStatLine([]byte(line)); err != nil {
+		t.Errorf("expected disabled collector not to be an error, got: %v", err)
+	}
+	if want, got := 0, len(re.keys()); want != got {
+		t.Errorf("want %d points, got %d", want, got)
+	}
+
+	broken := `2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"main Q","enqueued":"twenty"}`
+	if err := re.handleStatLine([]byte(broken)); err != nil {
+		t.Errorf("expected disabled collector to be skipped before decoding, got: %v", err)
+	}
+}
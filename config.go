@@ -0,0 +1,104 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+var (
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// config is the optional configuration file of the exporter, for settings
+// that do not fit into command line flags.
+type config struct {
+	Dynstats       []*dynStatRule     `json:"dynstats"`
+	Limits         *seriesLimits      `json:"limits"`
+	RelabelConfigs []*relabelConfig   `json:"relabel_configs"`
+	Aggregations   []*aggregationRule `json:"aggregations"`
+}
+
+func loadConfig(path string) (*config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	return parseConfig(b)
+}
+
+func parseConfig(b []byte) (*config, error) {
+	var c config
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %v", err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *config) validate() error {
+	buckets := map[string]bool{}
+	for idx, r := range c.Dynstats {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("invalid dynstats rule %d: %v", idx, err)
+		}
+		if buckets[r.Bucket] {
+			return fmt.Errorf("invalid dynstats rule %d: duplicate rule for bucket %q", idx, r.Bucket)
+		}
+		buckets[r.Bucket] = true
+	}
+	if c.Limits != nil {
+		if err := c.Limits.validate(); err != nil {
+			return fmt.Errorf("invalid limits: %v", err)
+		}
+	}
+	for idx, r := range c.RelabelConfigs {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("invalid relabel config %d: %v", idx, err)
+		}
+	}
+	for idx, r := range c.Aggregations {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("invalid aggregation %d: %v", idx, err)
+		}
+	}
+	return nil
+}
+
+// dynStatRules returns the dynstats rules keyed by bucket.
+func (c *config) dynStatRules() map[string]*dynStatRule {
+	rules := make(map[string]*dynStatRule, len(c.Dynstats))
+	for _, r := range c.Dynstats {
+		rules[r.Bucket] = r
+	}
+	return rules
+}
+
+func validateLabelName(name string) error {
+	if !labelNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid label name %q", name)
+	}
+	if len(name) > 1 && name[:2] == "__" {
+		return fmt.Errorf("label name %q is reserved", name)
+	}
+	return nil
+}
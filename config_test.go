@@ -0,0 +1,75 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig([]byte(`{
+		"dynstats": [
+			{"bucket": "msg_per_app", "delimiter": ".", "separator": "=", "labels": ["app", "sev"]},
+			{"bucket": "msg_per_host", "regex": "(?P<host>[^.]+)\\.(?P<domain>.+)", "fallback_label": "name"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("expected parsing config not to fail, got: %v", err)
+	}
+
+	rules := cfg.dynStatRules()
+	if want, got := 2, len(rules); want != got {
+		t.Fatalf("want %d rules, got %d", want, got)
+	}
+
+	if want, got := "counter", rules["msg_per_app"].FallbackLabel; want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+	if want, got := []string{"host", "domain"}, rules["msg_per_host"].labels; len(want) != len(got) || want[0] != got[0] || want[1] != got[1] {
+		t.Errorf("want '%v', got '%v'", want, got)
+	}
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"unknown field":     `{"unknown": true}`,
+		"no bucket":         `{"dynstats": [{"delimiter": ".", "labels": ["a"]}]}`,
+		"no split":          `{"dynstats": [{"bucket": "b", "labels": ["a"]}]}`,
+		"regex and delim":   `{"dynstats": [{"bucket": "b", "regex": "(?P<a>.*)", "delimiter": "."}]}`,
+		"invalid regex":     `{"dynstats": [{"bucket": "b", "regex": "(?P<a>.*"}]}`,
+		"no named groups":   `{"dynstats": [{"bucket": "b", "regex": "(.*)"}]}`,
+		"no labels":         `{"dynstats": [{"bucket": "b", "delimiter": "."}]}`,
+		"invalid label":     `{"dynstats": [{"bucket": "b", "delimiter": ".", "labels": ["a-b"]}]}`,
+		"reserved label":    `{"dynstats": [{"bucket": "b", "delimiter": ".", "labels": ["__a"]}]}`,
+		"duplicate label":   `{"dynstats": [{"bucket": "b", "delimiter": ".", "labels": ["a", "a"]}]}`,
+		"fallback conflict": `{"dynstats": [{"bucket": "b", "delimiter": ".", "labels": ["counter"]}]}`,
+		"same separator":    `{"dynstats": [{"bucket": "b", "delimiter": ".", "separator": ".", "labels": ["a"]}]}`,
+		"invalid overflow":  `{"limits": {"overflow": "panic"}}`,
+		"negative limit":    `{"limits": {"families": {"action_processed": -1}}}`,
+		"no target label":   `{"relabel_configs": [{"source_labels": ["action"]}]}`,
+		"unknown action":    `{"relabel_configs": [{"action": "hashmod"}]}`,
+		"keep no source":    `{"relabel_configs": [{"action": "keep", "regex": "a"}]}`,
+		"labeldrop target":  `{"relabel_configs": [{"action": "labeldrop", "target_label": "a"}]}`,
+		"relabel regex":     `{"relabel_configs": [{"action": "drop", "source_labels": ["a"], "regex": "("}]}`,
+		"no family":         `{"aggregations": [{"regex": "w.*", "target": "all"}]}`,
+		"no target":         `{"aggregations": [{"family": "input_received", "regex": "w.*"}]}`,
+		"aggregation regex": `{"aggregations": [{"family": "input_received", "regex": "(", "target": "all"}]}`,
+		"duplicate bucket":  `{"dynstats": [{"bucket": "b", "delimiter": ".", "labels": ["a"]}, {"bucket": "b", "delimiter": ".", "labels": ["a"]}]}`,
+	}
+
+	for name, c := range testCases {
+		if _, err := parseConfig([]byte(c)); err == nil {
+			t.Errorf("%s: expected parsing config to fail", name)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+var errDeadLetterRateLimited = errors.New("dead letter rate limit exceeded")
+
+// deadLetterFile records rejected stats lines as JSON lines. Once the file
+// would exceed maxSize, it is rotated to <path>.1, replacing the previous
+// rotated file. At most rateLimit lines are written per second.
+type deadLetterFile struct {
+	lock      sync.Mutex
+	path      string
+	maxSize   int64
+	rateLimit int
+
+	// file is nil if it could not be reopened after a rotation, in which
+	// case the next write tries again.
+	file   *os.File
+	size   int64
+	window time.Time
+	writes int
+
+	now func() time.Time
+}
+
+// deadLetter is a record of the dead letter file. As errors may contain the
+// object of the stats line, it is replaced by deadLetterObject in Error.
+type deadLetter struct {
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+	Origin string    `json:"origin,omitempty"`
+	Error  string    `json:"error"`
+	Line   string    `json:"line"`
+}
+
+const deadLetterObject = "<object>"
+
+func newDeadLetterFile(path string, maxSize int64, rateLimit int) (*deadLetterFile, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid maximum dead letter file size %d", maxSize)
+	}
+	if rateLimit <= 0 {
+		return nil, fmt.Errorf("invalid dead letter rate limit %d", rateLimit)
+	}
+	d := &deadLetterFile{
+		path:      path,
+		maxSize:   maxSize,
+		rateLimit: rateLimit,
+		now:       time.Now,
+	}
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *deadLetterFile) open() error {
+	f, err := os.OpenFile(d.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat dead letter file: %v", err)
+	}
+	d.file = f
+	d.size = info.Size()
+	return nil
+}
+
+// rotate moves the file to <path>.1 and opens a new file. The file at path is
+// reopened even if the rotation fails, so that later writes do not fail.
+func (d *deadLetterFile) rotate() error {
+	var err error
+	if closeErr := d.file.Close(); closeErr != nil {
+		err = fmt.Errorf("failed to close dead letter file: %v", closeErr)
+	} else if renameErr := os.Rename(d.path, d.path+".1"); renameErr != nil {
+		err = fmt.Errorf("failed to rotate dead letter file: %v", renameErr)
+	}
+	d.file = nil
+	if openErr := d.open(); openErr != nil {
+		return openErr
+	}
+	return err
+}
+
+// write records a line rejected with err. It returns
+// errDeadLetterRateLimited if the line was dropped due to the rate limit.
+func (d *deadLetterFile) write(line []byte, err error) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.now()
+	if window := now.Truncate(time.Second); !window.Equal(d.window) {
+		d.window = window
+		d.writes = 0
+	}
+	if d.writes >= d.rateLimit {
+		return errDeadLetterRateLimited
+	}
+	d.writes++
+
+	reason, origin := reasonOther, ""
+	var se *statLineError
+	if errors.As(err, &se) {
+		reason, origin = se.reason, se.origin
+	}
+	b, jsonErr := json.Marshal(deadLetter{
+		Time:   now,
+		Reason: reason,
+		Origin: origin,
+		Error:  deadLetterError(line, err),
+		Line:   string(line),
+	})
+	if jsonErr != nil {
+		return fmt.Errorf("failed to encode dead letter: %v", jsonErr)
+	}
+	b = append(b, '\n')
+
+	if d.file == nil {
+		if err := d.open(); err != nil {
+			return err
+		}
+	}
+	if d.size > 0 && d.size+int64(len(b)) > d.maxSize {
+		if err := d.rotate(); err != nil {
+			return err
+		}
+	}
+	n, writeErr := d.file.Write(b)
+	d.size += int64(n)
+	if writeErr != nil {
+		return fmt.Errorf("failed to write dead letter file: %v", writeErr)
+	}
+	return nil
+}
+
+func (d *deadLetterFile) close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.file == nil {
+		return nil
+	}
+	return d.file.Close()
+}
+
+// deadLetterError returns the message of err with the object of the line, as
+// embedded by decoding errors, replaced by deadLetterObject.
+func deadLetterError(line []byte, err error) string {
+	msg := err.Error()
+	if _, obj, err := impstats.SplitLine(line); err == nil && len(obj) > 0 {
+		msg = strings.ReplaceAll(msg, string(obj), deadLetterObject)
+	}
+	return msg
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func readDeadLetters(t *testing.T, path string) []deadLetter {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var letters []deadLetter
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var l deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			t.Fatalf("expected dead letter to be valid JSON, got: %v", err)
+		}
+		letters = append(letters, l)
+	}
+	return letters
+}
+
+func TestDeadLetterFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deadletters.jsonl")
+	d, err := newDeadLetterFile(path, 1<<20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+	now := time.Unix(1000, 0)
+	d.now = func() time.Time { return now }
+
+	lineErr := newStatLineError(reasonDecode, "core.queue", errors.New("bad value"))
+	if err := d.write([]byte("line 1"), lineErr); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.write([]byte("line 2"), errors.New("other error")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.write([]byte("line 3"), lineErr); err != errDeadLetterRateLimited {
+		t.Errorf("expected rate limit to be exceeded, got: %v", err)
+	}
+	now = now.Add(time.Second)
+	if err := d.write([]byte("line 4"), lineErr); err != nil {
+		t.Fatal(err)
+	}
+
+	letters := readDeadLetters(t, path)
+	if want, got := 3, len(letters); want != got {
+		t.Fatalf("want %d dead letters, got %d", want, got)
+	}
+	want := deadLetter{Time: time.Unix(1000, 0), Reason: reasonDecode, Origin: "core.queue", Error: "bad value", Line: "line 1"}
+	if got := letters[0]; !want.Time.Equal(got.Time) || want.Reason != got.Reason || want.Origin != got.Origin || want.Error != got.Error || want.Line != got.Line {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+	if want, got := reasonOther, letters[1].Reason; want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+	if want, got := "line 4", letters[2].Line; want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
+
+func TestDeadLetterFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deadletters.jsonl")
+	d, err := newDeadLetterFile(path, 300, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+
+	for i := 0; i < 3; i++ {
+		if err := d.write([]byte("a rejected stats line"), errors.New("bad line")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 300 {
+		t.Errorf("expected dead letter file to be rotated at 300 bytes, has %d", info.Size())
+	}
+	if want, got := 3, len(readDeadLetters(t, path))+len(readDeadLetters(t, path+".1")); want != got {
+		t.Errorf("want %d dead letters in total, got %d", want, got)
+	}
+}
+
+func TestDeadLetterFileRotationFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deadletters.jsonl")
+	d, err := newDeadLetterFile(path, 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+
+	// A non-empty directory in place of the rotated file makes rotation fail.
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocked"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.write([]byte("a rejected stats line"), errors.New("bad line")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.write([]byte("a rejected stats line"), errors.New("bad line")); err == nil {
+		t.Error("expected rotation to fail")
+	}
+	if err := d.write([]byte("a rejected stats line"), errors.New("bad line")); err == nil {
+		t.Error("expected rotation to fail again")
+	}
+
+	// Once rotation works again, lines are recorded again.
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.write([]byte("a later stats line"), errors.New("bad line")); err != nil {
+		t.Fatalf("expected writing after failed rotation to recover, got: %v", err)
+	}
+	letters := readDeadLetters(t, path)
+	if want, got := 1, len(letters); want != got {
+		t.Fatalf("want %d dead letters, got %d", want, got)
+	}
+	if want, got := "a later stats line", letters[0].Line; want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
+
+func TestDeadLetterError(t *testing.T) {
+	re := newRsyslogExporter()
+	line := []byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"a","origin":"core.action","processed":"ten"}`)
+	err := re.handleStatLine(line)
+	if err == nil {
+		t.Fatal("expected decoding to fail")
+	}
+
+	msg := deadLetterError(line, err)
+	if strings.Contains(msg, `"processed":"ten"`) {
+		t.Errorf("expected the object to be stripped from the error, got: %s", msg)
+	}
+	if !strings.Contains(msg, deadLetterObject) {
+		t.Errorf("expected the error to refer to the object, got: %s", msg)
+	}
+
+	if want, got := "bad value", deadLetterError([]byte("broken"), errors.New("bad value")); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
+
+// TestDeadLettersDroppedConcurrent is meant to be run with -race.
+func TestDeadLettersDroppedConcurrent(t *testing.T) {
+	d, err := newDeadLetterFile(filepath.Join(t.TempDir(), "dead-letters.log"), 1<<20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+	d.now = func() time.Time { return time.Unix(1000, 0) }
+
+	re := newRsyslogExporter()
+	re.deadLetters = d
+
+	// Drops counted concurrently have to be stored in order, or the stored
+	// count falls behind.
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				re.handleLine([]byte(`broken`), true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	p, err := re.get("dead_letters_dropped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(999), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
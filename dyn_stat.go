@@ -14,38 +14,222 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
 )
 
-type dynStat struct {
-	Name   string           `json:"name"`
-	Origin string           `json:"origin"`
-	Values map[string]int64 `json:"values"`
+const defaultDynStatFallbackLabel = "counter"
+
+// dynStatTracker follows the lifecycle of dynstats buckets. Once the global
+// dynstats object reports a purge of a bucket, the series of counters which
+// are missing from the next report of that bucket are stale, as rsyslog
+// removed them after their unusedMetricLife expired.
+type dynStatTracker struct {
+	lock    sync.Mutex
+	purges  map[string]int64
+	pending map[string]bool
+	series  map[string]map[string]bool
+}
+
+func newDynStatTracker() *dynStatTracker {
+	return &dynStatTracker{
+		purges:  make(map[string]int64),
+		pending: make(map[string]bool),
+		series:  make(map[string]map[string]bool),
+	}
+}
+
+// observeGlobal records the purge counts of the global dynstats object.
+func (t *dynStatTracker) observeGlobal(s *impstats.DynStat) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for bucket, count := range s.PurgeCounts() {
+		last, seen := t.purges[bucket]
+		if seen && count > last {
+			t.pending[bucket] = true
+		}
+		t.purges[bucket] = count
+	}
+}
+
+// observeBucket records the keys of the points of a bucket and returns the
+// keys of points that became stale due to a purge.
+func (t *dynStatTracker) observeBucket(bucket string, points []*impstats.Point) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	current := make(map[string]bool, len(points))
+	for _, p := range points {
+		current[p.Key()] = true
+	}
+
+	var stale []string
+	if t.pending[bucket] {
+		for key := range t.series[bucket] {
+			if !current[key] {
+				stale = append(stale, key)
+			}
+		}
+		delete(t.pending, bucket)
+		t.series[bucket] = current
+		return stale
+	}
+
+	if t.series[bucket] == nil {
+		t.series[bucket] = current
+		return nil
+	}
+	for key := range current {
+		t.series[bucket][key] = true
+	}
+	return nil
+}
+
+// dynStatRule splits the counter names of a dynstats bucket into labels,
+// either by a regular expression with named groups, or by a delimiter. With
+// only a delimiter set, counter names are split into the values of Labels in
+// order. With a separator set as well, counter names are split into
+// name/value pairs, e.g. "app=nginx.sev=err". Counter names that do not
+// match are exported with their name in the fallback label.
+type dynStatRule struct {
+	Bucket        string   `json:"bucket"`
+	Regex         string   `json:"regex,omitempty"`
+	Delimiter     string   `json:"delimiter,omitempty"`
+	Separator     string   `json:"separator,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+	FallbackLabel string   `json:"fallback_label,omitempty"`
+
+	regex  *regexp.Regexp
+	labels []string
 }
 
-func newDynStatFromJSON(b []byte) (*dynStat, error) {
-	var pstat dynStat
-	err := json.Unmarshal(b, &pstat)
-	if err != nil {
-		return nil, fmt.Errorf("error decoding values stat `%v`: %v", string(b), err)
+func (r *dynStatRule) validate() error {
+	if r.Bucket == "" {
+		return errors.New("bucket must be set")
+	}
+	if r.FallbackLabel == "" {
+		r.FallbackLabel = defaultDynStatFallbackLabel
+	}
+
+	switch {
+	case r.Regex != "" && r.Delimiter != "":
+		return errors.New("only one of regex and delimiter may be set")
+	case r.Regex != "":
+		if len(r.Labels) > 0 || r.Separator != "" {
+			return errors.New("labels and separator are taken from the named groups of regex")
+		}
+		re, err := regexp.Compile("^(?:" + r.Regex + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid regex: %v", err)
+		}
+		r.labels = nil
+		for _, name := range re.SubexpNames()[1:] {
+			if name != "" {
+				r.labels = append(r.labels, name)
+			}
+		}
+		r.regex = re
+	case r.Delimiter != "":
+		if r.Separator == r.Delimiter {
+			return errors.New("separator must differ from delimiter")
+		}
+		r.labels = r.Labels
+	default:
+		return errors.New("one of regex and delimiter must be set")
+	}
+
+	if len(r.labels) == 0 {
+		return errors.New("no labels defined")
+	}
+	seen := map[string]bool{r.FallbackLabel: true}
+	if err := validateLabelName(r.FallbackLabel); err != nil {
+		return err
 	}
-	return &pstat, nil
+	for _, name := range r.labels {
+		if err := validateLabelName(name); err != nil {
+			return err
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate label name %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
 }
 
-func (i *dynStat) toPoints() []*point {
-	points := make([]*point, 0, len(i.Values))
+// split returns the label values for a counter name in the order of labels,
+// or false if the name does not match the rule.
+func (r *dynStatRule) split(name string) ([]string, bool) {
+	values := make([]string, len(r.labels))
+
+	if r.regex != nil {
+		matches := r.regex.FindStringSubmatch(name)
+		if matches == nil {
+			return nil, false
+		}
+		idx := 0
+		for i, group := range r.regex.SubexpNames() {
+			if i > 0 && group != "" {
+				values[idx] = matches[i]
+				idx++
+			}
+		}
+		return values, true
+	}
 
-	for name, value := range i.Values {
-		points = append(points, &point{
-			Name:        fmt.Sprintf("dynstat_%s", i.Name),
-			Type:        counter,
-			Value:       value,
-			Description: fmt.Sprintf("dynamic statistic bucket %s", i.Name),
-			LabelName:   "counter",
-			LabelValue:  name,
-		})
+	parts := strings.Split(name, r.Delimiter)
+	if r.Separator == "" {
+		if len(parts) != len(r.labels) {
+			return nil, false
+		}
+		return parts, true
 	}
 
-	return points
+	set := make([]bool, len(r.labels))
+	for _, part := range parts {
+		key, value, ok := strings.Cut(part, r.Separator)
+		if !ok {
+			return nil, false
+		}
+		idx := -1
+		for i, l := range r.labels {
+			if l == key {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 || set[idx] {
+			return nil, false
+		}
+		values[idx] = value
+		set[idx] = true
+	}
+	return values, true
+}
+
+// apply replaces the counter label of a dynstats point by the labels of the
+// rule. All points of the bucket carry the same labels, those of counter
+// names not matching the rule only have the fallback label set.
+func (r *dynStatRule) apply(p *impstats.Point) {
+	name := p.LabelValue
+	values, ok := r.split(name)
+	fallback := ""
+	if !ok {
+		values = make([]string, len(r.labels))
+		fallback = name
+	}
+
+	p.LabelName = r.labels[0]
+	p.LabelValue = values[0]
+	p.ExtraLabels = make([]impstats.Label, 0, len(r.labels))
+	for i := 1; i < len(r.labels); i++ {
+		p.ExtraLabels = append(p.ExtraLabels, impstats.Label{Name: r.labels[i], Value: values[i]})
+	}
+	p.ExtraLabels = append(p.ExtraLabels, impstats.Label{Name: r.FallbackLabel, Value: fallback})
 }
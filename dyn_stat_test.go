@@ -16,113 +16,71 @@ package main
 import (
 	"reflect"
 	"testing"
-)
-
-func TestGetDynStat(t *testing.T) {
-	log := []byte(`{ "name": "global", "origin": "dynstats", "values": { "msg_per_host.ops_overflow": 1, "msg_per_host.new_metric_add": 3, "msg_per_host.no_metric": 0, "msg_per_host.metrics_purged": 0, "msg_per_host.ops_ignored": 0 } }`)
-	values := map[string]int64{
-		"msg_per_host.ops_overflow":   1,
-		"msg_per_host.new_metric_add": 3,
-		"msg_per_host.no_metric":      0,
-		"msg_per_host.metrics_purged": 0,
-		"msg_per_host.ops_ignored":    0,
-	}
-
-	if want, got := rsyslogDynStat, getStatType(log); want != got {
-		t.Errorf("detected pstat type should be %d but is %d", want, got)
-	}
 
-	pstat, err := newDynStatFromJSON(log)
-	if err != nil {
-		t.Fatalf("expected parsing dynamic stat not to fail, got: %v", err)
-	}
-
-	if want, got := "global", pstat.Name; want != got {
-		t.Errorf("invalid name, want '%s', got '%s'", want, got)
-	}
-
-	if want, got := values, pstat.Values; !reflect.DeepEqual(want, got) {
-		t.Errorf("unexpected values, want: %+v got: %+v", want, got)
-	}
-}
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
 
-func TestDynStatToPoints(t *testing.T) {
-	log := []byte(`{ "name": "global", "origin": "dynstats", "values": { "msg_per_host.ops_overflow": 1, "msg_per_host.new_metric_add": 3, "msg_per_host.no_metric": 0, "msg_per_host.metrics_purged": 0, "msg_per_host.ops_ignored": 0 } }`)
-	wants := map[string]point{
-		"msg_per_host.ops_overflow": point{
-			Name:        "dynstat_global",
-			Type:        counter,
-			Value:       1,
-			Description: "dynamic statistic bucket global",
-			LabelName:   "counter",
-			LabelValue:  "msg_per_host.ops_overflow",
+func TestDynStatRuleApply(t *testing.T) {
+	testCases := []struct {
+		rule   *dynStatRule
+		name   string
+		labels []string
+		values []string
+	}{
+		{
+			rule:   &dynStatRule{Bucket: "b", Delimiter: ".", Separator: "=", Labels: []string{"app", "sev"}},
+			name:   "app=nginx.sev=err",
+			labels: []string{"app", "sev", "counter"},
+			values: []string{"nginx", "err", ""},
 		},
-		"msg_per_host.new_metric_add": point{
-			Name:        "dynstat_global",
-			Type:        counter,
-			Value:       3,
-			Description: "dynamic statistic bucket global",
-			LabelName:   "counter",
-			LabelValue:  "msg_per_host.new_metric_add",
+		{
+			rule:   &dynStatRule{Bucket: "b", Delimiter: ".", Separator: "=", Labels: []string{"app", "sev"}},
+			name:   "sev=err",
+			labels: []string{"app", "sev", "counter"},
+			values: []string{"", "err", ""},
 		},
-		"msg_per_host.no_metric": point{
-			Name:        "dynstat_global",
-			Type:        counter,
-			Value:       0,
-			Description: "dynamic statistic bucket global",
-			LabelName:   "counter",
-			LabelValue:  "msg_per_host.no_metric",
+		{
+			rule:   &dynStatRule{Bucket: "b", Delimiter: ".", Separator: "=", Labels: []string{"app", "sev"}},
+			name:   "host=a",
+			labels: []string{"app", "sev", "counter"},
+			values: []string{"", "", "host=a"},
 		},
-		"msg_per_host.metrics_purged": point{
-			Name:        "dynstat_global",
-			Type:        counter,
-			Value:       0,
-			Description: "dynamic statistic bucket global",
-			LabelName:   "counter",
-			LabelValue:  "msg_per_host.metrics_purged",
+		{
+			rule:   &dynStatRule{Bucket: "b", Delimiter: ".", Labels: []string{"app", "sev"}},
+			name:   "nginx.err",
+			labels: []string{"app", "sev", "counter"},
+			values: []string{"nginx", "err", ""},
 		},
-		"msg_per_host.ops_ignored": point{
-			Name:        "dynstat_global",
-			Type:        counter,
-			Value:       0,
-			Description: "dynamic statistic bucket global",
-			LabelName:   "counter",
-			LabelValue:  "msg_per_host.ops_ignored",
+		{
+			rule:   &dynStatRule{Bucket: "b", Regex: `(?P<host>[^.]+)\.(?P<domain>.+)`, FallbackLabel: "name"},
+			name:   "node1.example.org",
+			labels: []string{"host", "domain", "name"},
+			values: []string{"node1", "example.org", ""},
+		},
+		{
+			rule:   &dynStatRule{Bucket: "b", Regex: `(?P<host>[^.]+)\.(?P<domain>.+)`, FallbackLabel: "name"},
+			name:   "localhost",
+			labels: []string{"host", "domain", "name"},
+			values: []string{"", "", "localhost"},
 		},
 	}
 
-	seen := map[string]bool{}
-	for name := range wants {
-		seen[name] = false
-	}
-
-	pstat, err := newDynStatFromJSON(log)
-	if err != nil {
-		t.Fatalf("expected parsing dyn stat not to fail, got: %v", err)
-	}
-
-	points := pstat.toPoints()
-	for _, got := range points {
-		key := got.LabelValue
-		want, ok := wants[key]
-		if !ok {
-			t.Errorf("unexpected point, got: %+v", got)
-			continue
+	for _, tc := range testCases {
+		if err := tc.rule.validate(); err != nil {
+			t.Fatalf("expected rule to be valid, got: %v", err)
 		}
-
-		if !reflect.DeepEqual(want, *got) {
-			t.Errorf("expected point to be %+v, got %+v", want, got)
+		p := &impstats.Point{
+			Name:       "dynstat_b",
+			LabelName:  "counter",
+			LabelValue: tc.name,
 		}
+		tc.rule.apply(p)
 
-		if seen[key] {
-			t.Errorf("point seen multiple times: %+v", got)
+		if want, got := tc.labels, p.PromLabelNames(); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: want labels %v, got %v", tc.name, want, got)
 		}
-		seen[key] = true
-	}
-
-	for name, ok := range seen {
-		if !ok {
-			t.Errorf("expected to see point with key %s, but did not", name)
+		if want, got := tc.values, p.PromLabelValues(); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: want values %v, got %v", tc.name, want, got)
 		}
 	}
 }
@@ -16,28 +16,15 @@ package main
 import (
 	"bufio"
 	"bytes"
-	"fmt"
+	"errors"
 	"log"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-)
 
-type rsyslogType int
-
-const (
-	rsyslogUnknown rsyslogType = iota
-	rsyslogAction
-	rsyslogInput
-	rsyslogQueue
-	rsyslogResource
-	rsyslogDynStat
-	rsyslogDynafileCache
-	rsyslogInputIMDUP
-	rsyslogForward
-	rsyslogKubernetes
-	rsyslogOmkafka
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
 )
 
 type rsyslogExporter struct {
@@ -45,120 +32,198 @@ type rsyslogExporter struct {
 	logfile *os.File
 	scanner *bufio.Scanner
 	pointStore
+
+	// inputSubmitted enables the input_submitted metric for objects handled
+	// as generic inputs by older versions.
+	inputSubmitted bool
+	// disabledCollectors are impstats types skipped without decoding.
+	disabledCollectors map[impstats.Type]bool
+	// namingScheme selects the legacy names, the conformant names or both.
+	// Points are stored by their legacy names, and named on collection.
+	namingScheme string
+	// impstatsTimestamps attaches the timestamps of stats lines to samples,
+	// unless they are further off the local clock than maxClockSkew.
+	impstatsTimestamps bool
+	maxClockSkew       time.Duration
+	// processMetrics enables exporting resource usage as process metrics.
+	processMetrics bool
+	// dynStatRules split dynstats counter names into labels, keyed by bucket.
+	dynStatRules map[string]*dynStatRule
+	dynStats     *dynStatTracker
+	objects      *objectTracker
+	lineErrors   *statLineErrors
+	// unmappedFields, if set, detects fields of stats lines not decoded.
+	unmappedFields *unmappedFields
+	// deadLetters, if set, records rejected stats lines.
+	deadLetters *deadLetterFile
+	// deadLettersDropped counts lines dropped by the rate limit of the dead
+	// letter file, guarded by deadLettersLock.
+	deadLettersLock    sync.Mutex
+	deadLettersDropped int64
+	// resourceUsage detects restarts of rsyslog by its resource usage.
+	resourceUsage restartDetector
+	// relabelConfigs are applied to all points before they are stored.
+	relabelConfigs []*relabelConfig
+	// aggregationRules sum up series after relabeling.
+	aggregationRules []*aggregationRule
+	// pipeline queues the lines read for the parser workers.
+	pipeline *pipeline
 }
 
 func newRsyslogExporter() *rsyslogExporter {
 	e := &rsyslogExporter{
-		scanner: bufio.NewScanner(os.Stdin),
-		pointStore: pointStore{
-			pointMap: make(map[string]*point),
-			lock:     &sync.RWMutex{},
+		scanner:      bufio.NewScanner(os.Stdin),
+		pointStore:   *newPointStore(),
+		namingScheme: namingLegacy,
+		dynStats:     newDynStatTracker(),
+		objects:      newObjectTracker(),
+		lineErrors:   newStatLineErrors(),
+		resourceUsage: restartDetector{
+			utime: make(map[string]int64),
 		},
 	}
 	return e
 }
 
+// inputSubmitter is implemented by objects that older versions exported as
+// generic inputs, see the inputSubmitted field.
+type inputSubmitter interface {
+	InputSubmittedPoint() *impstats.Point
+}
+
 func (re *rsyslogExporter) handleStatLine(rawbuf []byte) error {
-	s := bytes.SplitN(rawbuf, []byte(" "), 4)
-	if len(s) != 4 {
-		return fmt.Errorf("failed to split log line, expected 4 columns, got: %v", len(s))
+	ts, buf, err := impstats.SplitLine(rawbuf)
+	if err != nil {
+		return newStatLineError(reasonSplit, "", err)
 	}
-	buf := s[3]
 
-	pstatType := getStatType(buf)
+	var timestamp time.Time
+	if re.impstatsTimestamps {
+		timestamp = re.sampleTimestamp(ts)
+	}
 
-	switch pstatType {
-	case rsyslogAction:
-		a, err := newActionFromJSON(buf)
-		if err != nil {
-			return err
-		}
-		for _, p := range a.toPoints() {
-			re.set(p)
-		}
+	pstatType := impstats.DetectType(buf)
+	if re.disabledCollectors[pstatType] {
+		return nil
+	}
+	origin := impstats.Origin(buf)
+	// Lines without a name are rejected before decoding, so that they do not
+	// affect restart detection or dynstats bookkeeping either.
+	name := impstats.ObjectName(buf)
+	if name == "" && pstatType != impstats.TypeUnknown {
+		return newStatLineError(reasonInvalidName, origin, errors.New("stats line has no object name"))
+	}
 
-	case rsyslogInput:
-		i, err := newInputFromJSON(buf)
-		if err != nil {
-			return err
-		}
-		for _, p := range i.toPoints() {
-			re.set(p)
-		}
+	decoded, err := impstats.Decode(pstatType, buf)
+	if errors.Is(err, impstats.ErrUnknownType) {
+		return newStatLineError(reasonUnknownType, origin, err)
+	} else if err != nil {
+		return newStatLineError(reasonDecode, origin, err)
+	}
+	points := decoded.ToPoints()
 
-	case rsyslogInputIMDUP:
-		u, err := newInputIMUDPFromJSON(buf)
-		if err != nil {
-			return err
+	var dynStatBucket string
+	switch o := decoded.(type) {
+	case *impstats.Resource:
+		if re.resourceUsage.restarted(o) {
+			re.restarted()
 		}
-		for _, p := range u.toPoints() {
-			re.set(p)
+		if re.processMetrics {
+			points = append(points, o.ToProcessPoints()...)
 		}
-
-	case rsyslogQueue:
-		q, err := newQueueFromJSON(buf)
-		if err != nil {
-			return err
+	case *impstats.DynStat:
+		if o.IsGlobal() {
+			re.dynStats.observeGlobal(o)
+		} else {
+			if rule := re.dynStatRules[o.Name]; rule != nil {
+				for _, p := range points {
+					rule.apply(p)
+				}
+			}
+			dynStatBucket = o.Name
 		}
-		for _, p := range q.toPoints() {
+	}
+	if s, ok := decoded.(inputSubmitter); ok && re.inputSubmitted {
+		points = append(points, s.InputSubmittedPoint())
+	}
+	if re.unmappedFields != nil {
+		for _, p := range re.unmappedFields.check(origin, buf, decoded) {
 			re.set(p)
 		}
+	}
+	points = append(points, re.objects.observe(pstatType, origin, name, re.now())...)
 
-	case rsyslogResource:
-		r, err := newResourceFromJSON(buf)
-		if err != nil {
-			return err
-		}
-		for _, p := range r.toPoints() {
-			re.set(p)
-		}
-	case rsyslogDynStat:
-		s, err := newDynStatFromJSON(buf)
-		if err != nil {
-			return err
-		}
-		for _, p := range s.toPoints() {
-			re.set(p)
-		}
-	case rsyslogDynafileCache:
-		d, err := newDynafileCacheFromJSON(buf)
-		if err != nil {
-			return err
-		}
-		for _, p := range d.toPoints() {
-			re.set(p)
-		}
-	case rsyslogForward:
-		f, err := newForwardFromJSON(buf)
-		if err != nil {
-			return err
+	if !timestamp.IsZero() {
+		for _, p := range points {
+			p.Timestamp = timestamp
 		}
-		for _, p := range f.toPoints() {
-			re.set(p)
-		}
-	case rsyslogKubernetes:
-		k, err := newKubernetesFromJSON(buf)
-		if err != nil {
-			return err
-		}
-		for _, p := range k.toPoints() {
-			re.set(p)
-		}
-	case rsyslogOmkafka:
-		o, err := newOmkafkaFromJSON(buf)
-		if err != nil {
-			return err
+	}
+	points = re.relabelPoints(points)
+	if dynStatBucket != "" {
+		for _, key := range re.dynStats.observeBucket(dynStatBucket, points) {
+			re.delete(key)
 		}
-		for _, p := range o.toPoints() {
-			re.set(p)
+	}
+	for _, p := range points {
+		if rule, a := aggregate(re.aggregationRules, p); rule != nil {
+			re.aggregate(p.Key(), a)
+			if rule.DropOriginals {
+				continue
+			}
 		}
-
-	default:
-		return fmt.Errorf("unknown pstat type: %v", pstatType)
+		re.set(p)
 	}
 	return nil
 }
 
+// writeDeadLetter records a rejected line in the dead letter file, counting
+// lines dropped by its rate limit.
+func (re *rsyslogExporter) writeDeadLetter(line []byte, err error) {
+	switch dlErr := re.deadLetters.write(line, err); {
+	case errors.Is(dlErr, errDeadLetterRateLimited):
+		re.deadLettersLock.Lock()
+		re.deadLettersDropped++
+		re.set(&impstats.Point{
+			Name:        "dead_letters_dropped",
+			Type:        impstats.Counter,
+			Value:       re.deadLettersDropped,
+			Description: "rejected stats lines not written to the dead letter file due to its rate limit",
+		})
+		re.deadLettersLock.Unlock()
+	case dlErr != nil:
+		log.Printf("error writing dead letter: %v", dlErr)
+	}
+}
+
+// sampleTimestamp parses the timestamp of a stats line. It returns the zero
+// time, which means the scrape time is used, if the timestamp can not be
+// parsed or is further off the local clock than maxClockSkew.
+func (re *rsyslogExporter) sampleTimestamp(b []byte) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, string(b))
+	if err != nil {
+		return time.Time{}
+	}
+	if skew := re.now().Sub(t); skew > re.maxClockSkew || -skew > re.maxClockSkew {
+		return time.Time{}
+	}
+	return t
+}
+
+// relabelPoints applies the relabel configs to points, leaving out dropped
+// points.
+func (re *rsyslogExporter) relabelPoints(points []*impstats.Point) []*impstats.Point {
+	if len(re.relabelConfigs) == 0 {
+		return points
+	}
+	relabeled := make([]*impstats.Point, 0, len(points))
+	for _, p := range points {
+		if r := relabel(p, re.relabelConfigs); r != nil {
+			relabeled = append(relabeled, r)
+		}
+	}
+	return relabeled
+}
+
 // Describe sends the description of currently known metrics collected
 // by this Collector to the provided channel. Note that this implementation
 // does not necessarily send the "super-set of all possible descriptors" as
@@ -178,55 +243,71 @@ func (re *rsyslogExporter) Describe(ch chan<- *prometheus.Desc) {
 	for _, k := range keys {
 		p, err := re.get(k)
 		if err != nil {
-			ch <- p.promDescription()
+			ch <- p.PromDescription()
 		}
 	}
 }
 
 // Collect is called by Prometheus when collecting metrics.
 func (re *rsyslogExporter) Collect(ch chan<- prometheus.Metric) {
+	if re.pipeline != nil {
+		re.pipeline.store(re.set)
+	}
 	keys := re.keys()
+	now := re.now()
 
 	for _, k := range keys {
-		p, err := re.get(k)
+		stored, err := re.get(k)
 		if err != nil {
 			continue
 		}
 
-		labelValues := []string{}
-		if p.promLabelValue() != "" {
-			labelValues = []string{p.promLabelValue()}
+		for _, p := range applyNamingScheme(re.namingScheme, []*impstats.Point{stored}) {
+			metric, err := p.PromMetric()
+			if err != nil {
+				log.Printf("error creating metric %s: %v", p.Name, err)
+				continue
+			}
+			// A series rsyslog stopped reporting keeps its last timestamp,
+			// which is not attached once it is too old to be ingested.
+			if !p.Timestamp.IsZero() && now.Sub(p.Timestamp) <= re.maxClockSkew {
+				metric = prometheus.NewMetricWithTimestamp(p.Timestamp, metric)
+			}
+
+			ch <- metric
 		}
-		metric := prometheus.MustNewConstMetric(
-			p.promDescription(),
-			p.promType(),
-			p.promValue(),
-			labelValues...,
-		)
+	}
+}
 
-		ch <- metric
+// handleLine handles a stats line, accounting for errors.
+func (re *rsyslogExporter) handleLine(line []byte, silent bool) {
+	err := re.handleStatLine(line)
+	if err == nil {
+		return
+	}
+	re.lineErrors.count(err, re.set)
+	if !silent {
+		log.Printf("error handling stats line: %v, line was: %s", err, line)
+	}
+	if re.deadLetters != nil {
+		re.writeDeadLetter(line, err)
 	}
 }
 
 func (re *rsyslogExporter) run(silent bool) {
-	errorPoint := &point{
-		Name:        "stats_line_errors",
-		Type:        counter,
-		Description: "Counts errors during stats line handling",
+	for _, p := range re.lineErrors.initialPoints() {
+		re.set(p)
 	}
-	re.set(errorPoint)
+	re.pipeline.start(func(line []byte) {
+		re.handleLine(line, silent)
+	})
 	for re.scanner.Scan() {
-		err := re.handleStatLine(re.scanner.Bytes())
-		if err != nil {
-			errorPoint.Value += 1
-			if !silent {
-				log.Printf("error handling stats line: %v, line was: %s", err, re.scanner.Bytes())
-			}
-		}
+		re.pipeline.enqueue(bytes.Clone(re.scanner.Bytes()))
 	}
 	if err := re.scanner.Err(); err != nil {
 		log.Printf("error reading input: %v", err)
 	}
+	re.pipeline.close()
 	log.Print("input ended, exiting normally")
 	os.Exit(0)
 }
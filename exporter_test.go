@@ -15,7 +15,13 @@ package main
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func testHelper(t *testing.T, line []byte, testCase []*testUnit) {
@@ -27,12 +33,12 @@ func testHelper(t *testing.T, line []byte, testCase []*testUnit) {
 	}
 
 	for _, item := range testCase {
-		p, err := exporter.get(item.key())
+		p, err := item.find(exporter)
 		if err != nil {
 			t.Error(err)
 		}
 
-		if want, got := item.Val, p.promValue(); want != got {
+		if want, got := item.Val, p.PromValue(); want != got {
 			t.Errorf("%s: want '%f', got '%f'", item.Name, want, got)
 		}
 	}
@@ -40,64 +46,82 @@ func testHelper(t *testing.T, line []byte, testCase []*testUnit) {
 	exporter.handleStatLine(line)
 
 	for _, item := range testCase {
-		p, err := exporter.get(item.key())
+		p, err := item.find(exporter)
 		if err != nil {
 			t.Error(err)
 		}
 
 		var wanted float64
 		switch p.Type {
-		case counter:
+		case impstats.Counter:
 			wanted = item.Val
-		case gauge:
+		case impstats.Gauge:
 			wanted = item.Val
 		default:
 			t.Errorf("%d is not a valid metric type", p.Type)
 			continue
 		}
 
-		if want, got := wanted, p.promValue(); want != got {
+		if want, got := wanted, p.PromValue(); want != got {
 			t.Errorf("%s: want '%f', got '%f'", item.Name, want, got)
 		}
 	}
 }
 
 type testUnit struct {
-	Name       string
-	Val        float64
-	LabelValue string
+	Name             string
+	Val              float64
+	LabelValue       string
+	ExtraLabelValues []string
 }
 
-func (t *testUnit) key() string {
-	return fmt.Sprintf("%s.%s", t.Name, t.LabelValue)
+// find returns the stored point named as the unit with its label values, in
+// export order.
+func (t *testUnit) find(re *rsyslogExporter) (*impstats.Point, error) {
+	want := append([]string{t.LabelValue}, t.ExtraLabelValues...)
+	for _, k := range re.keys() {
+		p, err := re.get(k)
+		if err != nil {
+			return nil, err
+		}
+		if p.Name == t.Name && slices.Equal(p.PromLabelValues(), want) {
+			return p, nil
+		}
+	}
+	return &impstats.Point{}, fmt.Errorf("point %s%q does not exist", t.Name, want)
 }
 
 func TestHandleLineWithAction(t *testing.T) {
 	tests := []*testUnit{
 		&testUnit{
-			Name:       "action_processed",
-			Val:        100000,
-			LabelValue: "test_action",
+			Name:             "action_processed",
+			Val:              100000,
+			LabelValue:       "test_action",
+			ExtraLabelValues: []string{"", "", ""},
 		},
 		&testUnit{
-			Name:       "action_failed",
-			Val:        2,
-			LabelValue: "test_action",
+			Name:             "action_failed",
+			Val:              2,
+			LabelValue:       "test_action",
+			ExtraLabelValues: []string{"", "", ""},
 		},
 		&testUnit{
-			Name:       "action_suspended",
-			Val:        1,
-			LabelValue: "test_action",
+			Name:             "action_suspended",
+			Val:              1,
+			LabelValue:       "test_action",
+			ExtraLabelValues: []string{"", "", ""},
 		},
 		&testUnit{
-			Name:       "action_suspended_duration",
-			Val:        1000,
-			LabelValue: "test_action",
+			Name:             "action_suspended_duration",
+			Val:              1000,
+			LabelValue:       "test_action",
+			ExtraLabelValues: []string{"", "", ""},
 		},
 		&testUnit{
-			Name:       "action_resumed",
-			Val:        1,
-			LabelValue: "test_action",
+			Name:             "action_resumed",
+			Val:              1,
+			LabelValue:       "test_action",
+			ExtraLabelValues: []string{"", "", ""},
 		},
 	}
 
@@ -174,34 +198,40 @@ func TestHandleLineWithInput(t *testing.T) {
 func TestHandleLineWithQueue(t *testing.T) {
 	tests := []*testUnit{
 		&testUnit{
-			Name:       "queue_size",
-			Val:        10,
-			LabelValue: "main Q",
+			Name:             "queue_size",
+			Val:              10,
+			LabelValue:       "main Q",
+			ExtraLabelValues: []string{"main", "main", "false"},
 		},
 		&testUnit{
-			Name:       "queue_enqueued",
-			Val:        20,
-			LabelValue: "main Q",
+			Name:             "queue_enqueued",
+			Val:              20,
+			LabelValue:       "main Q",
+			ExtraLabelValues: []string{"main", "main", "false"},
 		},
 		&testUnit{
-			Name:       "queue_full",
-			Val:        30,
-			LabelValue: "main Q",
+			Name:             "queue_full",
+			Val:              30,
+			LabelValue:       "main Q",
+			ExtraLabelValues: []string{"main", "main", "false"},
 		},
 		&testUnit{
-			Name:       "queue_discarded_full",
-			Val:        40,
-			LabelValue: "main Q",
+			Name:             "queue_discarded_full",
+			Val:              40,
+			LabelValue:       "main Q",
+			ExtraLabelValues: []string{"main", "main", "false"},
 		},
 		&testUnit{
-			Name:       "queue_discarded_not_full",
-			Val:        50,
-			LabelValue: "main Q",
+			Name:             "queue_discarded_not_full",
+			Val:              50,
+			LabelValue:       "main Q",
+			ExtraLabelValues: []string{"main", "main", "false"},
 		},
 		&testUnit{
-			Name:       "queue_max_size",
-			Val:        60,
-			LabelValue: "main Q",
+			Name:             "queue_max_size",
+			Val:              60,
+			LabelValue:       "main Q",
+			ExtraLabelValues: []string{"main", "main", "false"},
 		},
 	}
 
@@ -212,29 +242,29 @@ func TestHandleLineWithQueue(t *testing.T) {
 func TestHandleLineWithGlobal(t *testing.T) {
 	tests := []*testUnit{
 		&testUnit{
-			Name:       "dynstat_global",
+			Name:       "dynstats_ops_overflow",
 			Val:        1,
-			LabelValue: "msg_per_host.ops_overflow",
+			LabelValue: "msg_per_host",
 		},
 		&testUnit{
-			Name:       "dynstat_global",
+			Name:       "dynstats_new_metric_add",
 			Val:        3,
-			LabelValue: "msg_per_host.new_metric_add",
+			LabelValue: "msg_per_host",
 		},
 		&testUnit{
-			Name:       "dynstat_global",
+			Name:       "dynstats_no_metric",
 			Val:        0,
-			LabelValue: "msg_per_host.no_metric",
+			LabelValue: "msg_per_host",
 		},
 		&testUnit{
-			Name:       "dynstat_global",
+			Name:       "dynstats_metrics_purged",
 			Val:        0,
-			LabelValue: "msg_per_host.metrics_purged",
+			LabelValue: "msg_per_host",
 		},
 		&testUnit{
-			Name:       "dynstat_global",
+			Name:       "dynstats_ops_ignored",
 			Val:        0,
-			LabelValue: "msg_per_host.ops_ignored",
+			LabelValue: "msg_per_host",
 		},
 	}
 
@@ -281,3 +311,242 @@ func TestHandleUnknown(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 }
+
+func TestHandleLineWithIMJournal(t *testing.T) {
+	tests := []*testUnit{
+		&testUnit{
+			Name:       "imjournal_submitted",
+			Val:        1000,
+			LabelValue: "imjournal",
+		},
+		&testUnit{
+			Name:       "imjournal_recovery_attempts",
+			Val:        5,
+			LabelValue: "imjournal",
+		},
+		&testUnit{
+			Name:       "imjournal_disk_usage_bytes",
+			Val:        104857600,
+			LabelValue: "imjournal",
+		},
+	}
+
+	log := []byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: { "name": "imjournal", "origin": "imjournal", "submitted": 1000, "read": 1010, "discarded": 3, "failed": 2, "poll_failed": 1, "rotations": 4, "recovery_attempts": 5, "ratelimit_discarded_in_interval": 7, "disk_usage_bytes": 104857600 }`)
+	testHelper(t, log, tests)
+}
+
+func TestHandleLineWithInputSubmittedCompat(t *testing.T) {
+	prefix := "2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: "
+	for key, obj := range map[string]string{
+		`input_submitted{input="imjournal"}`:                            `{ "name": "imjournal", "origin": "imjournal", "submitted": 1000, "read": 1010, "discarded": 3, "failed": 2, "poll_failed": 1, "rotations": 4, "recovery_attempts": 5, "ratelimit_discarded_in_interval": 7, "disk_usage_bytes": 104857600 }`,
+		`input_submitted{input="imkafka[logs_kafka-1:9092_consumers]"}`: `{ "name": "imkafka[logs_kafka-1:9092_consumers]", "origin": "imkafka", "submitted": 120, "received": 123, "failures": 3, "eof": 4, "poll_empty": 56, "maxlag": 42 }`,
+		`input_submitted{input="kafka_out"}`:                            `{ "name": "kafka_out", "origin": "omkafka", "submitted": 12, "maxoutqsize": 3 }`,
+		`input_submitted{input="omelasticsearch"}`:                      `{ "name": "omelasticsearch", "origin": "omelasticsearch", "submitted": 1000, "failed.http": 1, "failed.httprequests": 2, "failed.checkConn": 3, "failed.es": 4, "response.success": 900, "response.bad": 5, "response.duplicate": 6, "response.badargument": 7, "response.bulkrejection": 80, "response.other": 2, "rebinds": 9 }`,
+	} {
+		line := []byte(prefix + obj)
+
+		exporter := newRsyslogExporter()
+		exporter.handleStatLine(line)
+		if _, err := exporter.get(key); err != errPointNotFound {
+			t.Errorf("%s should only be exported in compatibility mode", key)
+		}
+
+		exporter.inputSubmitted = true
+		exporter.handleStatLine(line)
+		if _, err := exporter.get(key); err != nil {
+			t.Errorf("%s should be exported in compatibility mode: %v", key, err)
+		}
+	}
+}
+
+func TestHandleLineWithDynStatPurge(t *testing.T) {
+	prefix := `2018-01-18T09:39:12.763025+00:00 some-node.example.org rsyslogd-pstats: `
+	lines := []string{
+		`{ "name": "global", "origin": "dynstats", "values": { "msg_per_host.purge_triggered": 0, "msg_per_host.metrics_purged": 0 } }`,
+		`{ "name": "msg_per_host", "origin": "dynstats.bucket", "values": { "host1": 10, "host2": 20 } }`,
+		`{ "name": "msg_per_host", "origin": "dynstats.bucket", "values": { "host1": 11 } }`,
+	}
+
+	exporter := newRsyslogExporter()
+	for _, line := range lines {
+		if err := exporter.handleStatLine([]byte(prefix + line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Counters missing without a purge are kept.
+	if _, err := exporter.get(`dynstat_msg_per_host{counter="host2"}`); err != nil {
+		t.Errorf("want dynstat_msg_per_host.host2 to be kept, got: %v", err)
+	}
+
+	lines = []string{
+		`{ "name": "global", "origin": "dynstats", "values": { "msg_per_host.purge_triggered": 1, "msg_per_host.metrics_purged": 1 } }`,
+		`{ "name": "msg_per_host", "origin": "dynstats.bucket", "values": { "host1": 12 } }`,
+	}
+	for _, line := range lines {
+		if err := exporter.handleStatLine([]byte(prefix + line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := exporter.get(`dynstat_msg_per_host{counter="host2"}`); err != errPointNotFound {
+		t.Errorf("want dynstat_msg_per_host.host2 to be removed after purge")
+	}
+
+	p, err := exporter.get(`dynstat_msg_per_host{counter="host1"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(12), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+
+	p, err = exporter.get(`dynstats_purge_triggered{bucket="msg_per_host"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(1), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
+
+func TestHandleLineWithRelabel(t *testing.T) {
+	cfg, err := parseConfig([]byte(`{
+		"relabel_configs": [
+			{"source_labels": ["__name__", "action"], "regex": "action_.*;action \\d+", "action": "drop"},
+			{"source_labels": ["destination"], "regex": "(.*)-\\d+", "target_label": "destination"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("expected parsing config not to fail, got: %v", err)
+	}
+
+	re := newRsyslogExporter()
+	re.relabelConfigs = cfg.RelabelConfigs
+
+	lines := []string{
+		`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"action 3","origin":"core.action","processed":0,"failed":0,"suspended":0,"suspended.duration":0,"resumed":0}`,
+		`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"to_exporter","origin":"core.action","processed":5,"failed":0,"suspended":0,"suspended.duration":0,"resumed":0}`,
+		`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"TCP-logs.example.org-514","origin":"omfwd","bytes.sent":100}`,
+	}
+	for _, line := range lines {
+		if err := re.handleStatLine([]byte(line)); err != nil {
+			t.Fatalf("expected handling line not to fail, got: %v", err)
+		}
+	}
+
+	for _, key := range re.keys() {
+		if strings.Contains(key, `action="action 3"`) {
+			t.Errorf("expected idle numbered action to be dropped, got %s", key)
+		}
+	}
+	if _, err := re.get(`action_processed{action="to_exporter",action_index="",builtin="",module=""}`); err != nil {
+		t.Errorf("expected named action to be kept, got: %v", err)
+	}
+	if _, err := re.get(`forward_bytes_total{destination="TCP-logs.example.org"}`); err != nil {
+		t.Errorf("expected port to be stripped from destination, got: %v", err)
+	}
+}
+
+func TestHandleLineWithTimestamps(t *testing.T) {
+	re := newRsyslogExporter()
+	re.impstatsTimestamps = true
+	re.maxClockSkew = 5 * time.Minute
+	re.now = func() time.Time { return time.Date(2017, 8, 30, 8, 12, 0, 0, time.UTC) }
+
+	lines := []string{
+		`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"test_input","origin":"imuxsock","submitted":100}`,
+		`2017-08-30T08:09:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"test_input","origin":"imuxsock","submitted":50}`,
+	}
+	for _, line := range lines {
+		if err := re.handleStatLine([]byte(line)); err != nil {
+			t.Fatalf("expected handling line not to fail, got: %v", err)
+		}
+	}
+
+	p, err := re.get(`input_submitted{input="test_input"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := time.Date(2017, 8, 30, 8, 10, 4, 786350000, time.UTC), p.Timestamp; !want.Equal(got) {
+		t.Errorf("want '%v', got '%v'", want, got)
+	}
+	if want, got := int64(100), p.Value; want != got {
+		t.Errorf("expected out of order line to be ignored, want '%d', got '%d'", want, got)
+	}
+
+	skewed := `2017-08-30T07:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"test_input","origin":"imuxsock","submitted":150}`
+	if err := re.handleStatLine([]byte(skewed)); err != nil {
+		t.Fatalf("expected handling line not to fail, got: %v", err)
+	}
+	p, err = re.get(`input_submitted{input="test_input"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Timestamp.IsZero() {
+		t.Errorf("expected skewed timestamp not to be attached, got '%v'", p.Timestamp)
+	}
+	if want, got := int64(150), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
+
+func TestCollectDropsStaleTimestamps(t *testing.T) {
+	now := time.Date(2017, 8, 30, 8, 12, 0, 0, time.UTC)
+	re := newRsyslogExporter()
+	re.impstatsTimestamps = true
+	re.maxClockSkew = 5 * time.Minute
+	re.now = func() time.Time { return now }
+
+	line := `2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"test_input","origin":"imuxsock","submitted":100}`
+	if err := re.handleStatLine([]byte(line)); err != nil {
+		t.Fatalf("expected handling line not to fail, got: %v", err)
+	}
+
+	timestamp := func() int64 {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(re)
+		mfs, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, mf := range mfs {
+			if mf.GetName() == "rsyslog_input_submitted" {
+				return mf.GetMetric()[0].GetTimestampMs()
+			}
+		}
+		t.Fatal("expected input_submitted to be exported")
+		return 0
+	}
+
+	if want, got := time.Date(2017, 8, 30, 8, 10, 4, 786000000, time.UTC).UnixMilli(), timestamp(); want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+
+	// rsyslog stopped reporting the input, its last timestamp gets too old.
+	now = now.Add(10 * time.Minute)
+	if got := timestamp(); got != 0 {
+		t.Errorf("expected stale timestamp not to be attached, got '%d'", got)
+	}
+}
+
+func TestOmkafkaActionsDoNotCollide(t *testing.T) {
+	exporter := newRsyslogExporter()
+	exporter.handleStatLine([]byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: { "name": "omkafka", "origin": "omkafka", "submitted": 59, "maxoutqsize": 9, "failures": 0, "topicdynacache.skipped": 57, "topicdynacache.miss": 2, "topicdynacache.evicted": 0, "acked": 55, "failures_msg_too_large": 0, "failures_unknown_topic": 0, "failures_queue_full": 0, "failures_unknown_partition": 0, "failures_other": 0, "errors_timed_out": 0, "errors_transport": 0, "errors_broker_down": 0, "errors_auth": 0, "errors_ssl": 0, "errors_other": 0, "rtt_avg_usec": 0, "throttle_avg_msec": 0, "int_latency_avg_usec": 0 }`))
+	exporter.handleStatLine([]byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: { "name": "kafka_out", "origin": "omkafka", "submitted": 12, "maxoutqsize": 3, "failures": 0, "topicdynacache.skipped": 0, "topicdynacache.miss": 0, "topicdynacache.evicted": 0, "acked": 12, "failures_msg_too_large": 0, "failures_unknown_topic": 0, "failures_queue_full": 0, "failures_unknown_partition": 0, "failures_other": 0, "errors_timed_out": 0, "errors_transport": 0, "errors_broker_down": 0, "errors_auth": 0, "errors_ssl": 0, "errors_other": 0, "rtt_avg_usec": 0, "throttle_avg_msec": 0, "int_latency_avg_usec": 0 }`))
+
+	for key, want := range map[string]int64{
+		`omkafka_messages{action="omkafka",type="submitted"}`:   59,
+		`omkafka_messages{action="kafka_out",type="submitted"}`: 12,
+		`omkafka_maxoutqsize{action="kafka_out"}`:               3,
+	} {
+		p, err := exporter.get(key)
+		if err != nil {
+			t.Errorf("%s: %v", key, err)
+			continue
+		}
+		if got := p.Value; want != got {
+			t.Errorf("%s: want '%d', got '%d'", key, want, got)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// rsyslog names unnamed actions "action-<index>-<module>", where builtin
+	// modules are prefixed with "builtin:". Older versions use "action <index>".
+	actionNameRegexp       = regexp.MustCompile(`^action-(\d+)-(builtin:)?(\S+)$`)
+	legacyActionNameRegexp = regexp.MustCompile(`^action (\d+)$`)
+)
+
+// Action holds the statistics of an action, reported with origin core.action.
+type Action struct {
+	Name              string `json:"name"`
+	Processed         int64  `json:"processed"`
+	Failed            int64  `json:"failed"`
+	Suspended         int64  `json:"suspended"`
+	SuspendedDuration int64  `json:"suspended.duration"`
+	Resumed           int64  `json:"resumed"`
+}
+
+// NewActionFromJSON decodes the impstats object of an action.
+func NewActionFromJSON(b []byte) (*Action, error) {
+	var pstat Action
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode action stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+// nameLabels decodes action names following rsyslog's naming scheme for
+// unnamed actions into index, module and builtin labels. The labels are empty
+// for user given names and for parts older naming schemes do not carry.
+func (a *Action) nameLabels() []Label {
+	var index, module, builtin string
+	if matches := actionNameRegexp.FindStringSubmatch(a.Name); matches != nil {
+		index = matches[1]
+		module = matches[3]
+		builtin = strconv.FormatBool(matches[2] != "")
+	} else if matches := legacyActionNameRegexp.FindStringSubmatch(a.Name); matches != nil {
+		index = matches[1]
+	}
+	return []Label{
+		{Name: "action_index", Value: index},
+		{Name: "module", Value: module},
+		{Name: "builtin", Value: builtin},
+	}
+}
+
+// ToPoints returns the counters of the action, labelled by its name and, for
+// actions named by rsyslog, by their index and module.
+func (a *Action) ToPoints() []*Point {
+	points := make([]*Point, 5)
+	labels := a.nameLabels()
+
+	points[0] = &Point{
+		Name:        "action_processed",
+		Type:        Counter,
+		Value:       a.Processed,
+		Description: "messages processed",
+		LabelName:   "action",
+		LabelValue:  a.Name,
+		ExtraLabels: labels,
+	}
+
+	points[1] = &Point{
+		Name:        "action_failed",
+		Type:        Counter,
+		Value:       a.Failed,
+		Description: "messages failed",
+		LabelName:   "action",
+		LabelValue:  a.Name,
+		ExtraLabels: labels,
+	}
+
+	points[2] = &Point{
+		Name:        "action_suspended",
+		Type:        Counter,
+		Value:       a.Suspended,
+		Description: "times suspended",
+		LabelName:   "action",
+		LabelValue:  a.Name,
+		ExtraLabels: labels,
+	}
+
+	points[3] = &Point{
+		Name:        "action_suspended_duration",
+		Type:        Counter,
+		Value:       a.SuspendedDuration,
+		Description: "time spent suspended",
+		LabelName:   "action",
+		LabelValue:  a.Name,
+		ExtraLabels: labels,
+	}
+
+	points[4] = &Point{
+		Name:        "action_resumed",
+		Type:        Counter,
+		Value:       a.Resumed,
+		Description: "times resumed",
+		LabelName:   "action",
+		LabelValue:  a.Name,
+		ExtraLabels: labels,
+	}
+
+	return points
+}
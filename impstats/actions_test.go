@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import "testing"
 
@@ -20,12 +20,12 @@ var (
 )
 
 func TestNewActionFromJSON(t *testing.T) {
-	logType := getStatType(actionLog)
-	if logType != rsyslogAction {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogAction, logType)
+	logType := DetectType(actionLog)
+	if logType != TypeAction {
+		t.Errorf("detected pstat type should be %d but is %d", TypeAction, logType)
 	}
 
-	pstat, err := newActionFromJSON([]byte(actionLog))
+	pstat, err := NewActionFromJSON([]byte(actionLog))
 	if err != nil {
 		t.Fatalf("expected parsing action not to fail, got: %v", err)
 	}
@@ -56,11 +56,11 @@ func TestNewActionFromJSON(t *testing.T) {
 }
 
 func TestActionToPoints(t *testing.T) {
-	pstat, err := newActionFromJSON([]byte(actionLog))
+	pstat, err := NewActionFromJSON([]byte(actionLog))
 	if err != nil {
 		t.Fatalf("expected parsing action not to fail, got: %v", err)
 	}
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 
 	point := points[0]
 	if want, got := "action_processed", point.Name; want != got {
@@ -71,7 +71,7 @@ func TestActionToPoints(t *testing.T) {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
@@ -88,7 +88,7 @@ func TestActionToPoints(t *testing.T) {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
@@ -105,7 +105,7 @@ func TestActionToPoints(t *testing.T) {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
@@ -122,7 +122,7 @@ func TestActionToPoints(t *testing.T) {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
@@ -139,7 +139,7 @@ func TestActionToPoints(t *testing.T) {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
@@ -147,3 +147,35 @@ func TestActionToPoints(t *testing.T) {
 		t.Errorf("wanted '%s', got '%s'", want, got)
 	}
 }
+
+func TestActionNameLabels(t *testing.T) {
+	testCases := []struct {
+		name    string
+		index   string
+		module  string
+		builtin string
+	}{
+		{"action-3-builtin:omfile", "3", "omfile", "true"},
+		{"action-12-omkafka", "12", "omkafka", "false"},
+		{"action 7", "7", "", ""},
+		{"to_exporter", "", "", ""},
+		{"action-to-exporter", "", "", ""},
+	}
+
+	for _, tc := range testCases {
+		a := &Action{Name: tc.name}
+		labels := a.nameLabels()
+		if want, got := 3, len(labels); want != got {
+			t.Fatalf("%s: want %d labels, got %d", tc.name, want, got)
+		}
+		for idx, want := range []Label{
+			{Name: "action_index", Value: tc.index},
+			{Name: "module", Value: tc.module},
+			{Name: "builtin", Value: tc.builtin},
+		} {
+			if got := labels[idx]; want != got {
+				t.Errorf("%s: want %+v, got %+v", tc.name, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// dynStatBookkeeping lists the counters rsyslog reports per bucket in the
+// global dynstats object as "<bucket>.<counter>".
+var dynStatBookkeeping = []struct {
+	counter     string
+	description string
+}{
+	{"ops_overflow", "operations ignored because the bucket reached its maximum number of metrics"},
+	{"new_metric_add", "metrics added to the bucket"},
+	{"no_metric", "operations on metrics that do not exist in the bucket"},
+	{"metrics_purged", "metrics removed from the bucket by purges"},
+	{"ops_ignored", "operations ignored due to errors"},
+	{"purge_triggered", "times the bucket was purged of unused metrics"},
+}
+
+// DynStat holds the counters of a dynstats bucket, or the bookkeeping counters
+// of all buckets reported by the global dynstats object.
+type DynStat struct {
+	Name   string           `json:"name"`
+	Origin string           `json:"origin"`
+	Values map[string]int64 `json:"values"`
+}
+
+// NewDynStatFromJSON decodes the impstats object of a dynstats bucket or the
+// global dynstats object.
+func NewDynStatFromJSON(b []byte) (*DynStat, error) {
+	var pstat DynStat
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding values stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+// IsGlobal returns whether the stat is the global dynstats object carrying
+// the bookkeeping counters of all buckets.
+func (i *DynStat) IsGlobal() bool {
+	return i.Name == "global" && i.Origin == "dynstats"
+}
+
+// splitDynStatBookkeeping splits a value name of the global dynstats object into
+// the bucket and bookkeeping counter. rsyslog versions differ in whether they
+// separate the words of the ops counters by "_" or ".".
+func splitDynStatBookkeeping(name string) (bucket string, idx int, ok bool) {
+	for idx, b := range dynStatBookkeeping {
+		for _, suffix := range []string{b.counter, strings.Replace(b.counter, "_", ".", 1)} {
+			if bucket, found := strings.CutSuffix(name, "."+suffix); found && bucket != "" {
+				return bucket, idx, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// ToPoints returns the counters of the bucket, labelled by counter name, or the
+// bookkeeping counters of the global object, labelled by bucket.
+func (i *DynStat) ToPoints() []*Point {
+	points := make([]*Point, 0, len(i.Values))
+
+	for name, value := range i.Values {
+		if i.IsGlobal() {
+			if bucket, idx, ok := splitDynStatBookkeeping(name); ok {
+				points = append(points, &Point{
+					Name:        fmt.Sprintf("dynstats_%s", dynStatBookkeeping[idx].counter),
+					Type:        Counter,
+					Value:       value,
+					Description: dynStatBookkeeping[idx].description,
+					LabelName:   "bucket",
+					LabelValue:  bucket,
+				})
+				continue
+			}
+		}
+
+		points = append(points, &Point{
+			Name:        fmt.Sprintf("dynstat_%s", i.Name),
+			Type:        Counter,
+			Value:       value,
+			Description: fmt.Sprintf("dynamic statistic bucket %s", i.Name),
+			LabelName:   "counter",
+			LabelValue:  name,
+		})
+	}
+
+	return points
+}
+
+// PurgeCounts returns the number of purges reported per bucket by the global
+// dynstats object, taking the larger of purge_triggered and metrics_purged.
+func (i *DynStat) PurgeCounts() map[string]int64 {
+	counts := map[string]int64{}
+	for name, value := range i.Values {
+		bucket, idx, ok := splitDynStatBookkeeping(name)
+		if !ok {
+			continue
+		}
+		switch dynStatBookkeeping[idx].counter {
+		case "purge_triggered", "metrics_purged":
+			if last, ok := counts[bucket]; !ok || value > last {
+				counts[bucket] = value
+			}
+		}
+	}
+	return counts
+}
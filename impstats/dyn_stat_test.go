@@ -0,0 +1,128 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetDynStat(t *testing.T) {
+	log := []byte(`{ "name": "global", "origin": "dynstats", "values": { "msg_per_host.ops_overflow": 1, "msg_per_host.new_metric_add": 3, "msg_per_host.no_metric": 0, "msg_per_host.metrics_purged": 0, "msg_per_host.ops_ignored": 0 } }`)
+	values := map[string]int64{
+		"msg_per_host.ops_overflow":   1,
+		"msg_per_host.new_metric_add": 3,
+		"msg_per_host.no_metric":      0,
+		"msg_per_host.metrics_purged": 0,
+		"msg_per_host.ops_ignored":    0,
+	}
+
+	if want, got := TypeDynStat, DetectType(log); want != got {
+		t.Errorf("detected pstat type should be %d but is %d", want, got)
+	}
+
+	pstat, err := NewDynStatFromJSON(log)
+	if err != nil {
+		t.Fatalf("expected parsing dynamic stat not to fail, got: %v", err)
+	}
+
+	if want, got := "global", pstat.Name; want != got {
+		t.Errorf("invalid name, want '%s', got '%s'", want, got)
+	}
+
+	if want, got := values, pstat.Values; !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected values, want: %+v got: %+v", want, got)
+	}
+}
+
+func TestDynStatToPoints(t *testing.T) {
+	log := []byte(`{ "name": "global", "origin": "dynstats", "values": { "msg_per_host.ops_overflow": 1, "msg_per_host.new_metric_add": 3, "msg_per_host.no_metric": 0, "msg_per_host.metrics_purged": 0, "msg_per_host.ops_ignored": 0 } }`)
+	wants := map[string]Point{
+		"dynstats_ops_overflow": Point{
+			Name:        "dynstats_ops_overflow",
+			Type:        Counter,
+			Value:       1,
+			Description: "operations ignored because the bucket reached its maximum number of metrics",
+			LabelName:   "bucket",
+			LabelValue:  "msg_per_host",
+		},
+		"dynstats_new_metric_add": Point{
+			Name:        "dynstats_new_metric_add",
+			Type:        Counter,
+			Value:       3,
+			Description: "metrics added to the bucket",
+			LabelName:   "bucket",
+			LabelValue:  "msg_per_host",
+		},
+		"dynstats_no_metric": Point{
+			Name:        "dynstats_no_metric",
+			Type:        Counter,
+			Value:       0,
+			Description: "operations on metrics that do not exist in the bucket",
+			LabelName:   "bucket",
+			LabelValue:  "msg_per_host",
+		},
+		"dynstats_metrics_purged": Point{
+			Name:        "dynstats_metrics_purged",
+			Type:        Counter,
+			Value:       0,
+			Description: "metrics removed from the bucket by purges",
+			LabelName:   "bucket",
+			LabelValue:  "msg_per_host",
+		},
+		"dynstats_ops_ignored": Point{
+			Name:        "dynstats_ops_ignored",
+			Type:        Counter,
+			Value:       0,
+			Description: "operations ignored due to errors",
+			LabelName:   "bucket",
+			LabelValue:  "msg_per_host",
+		},
+	}
+
+	seen := map[string]bool{}
+	for name := range wants {
+		seen[name] = false
+	}
+
+	pstat, err := NewDynStatFromJSON(log)
+	if err != nil {
+		t.Fatalf("expected parsing dyn stat not to fail, got: %v", err)
+	}
+
+	points := pstat.ToPoints()
+	for _, got := range points {
+		key := got.Name
+		want, ok := wants[key]
+		if !ok {
+			t.Errorf("unexpected point, got: %+v", got)
+			continue
+		}
+
+		if !reflect.DeepEqual(want, *got) {
+			t.Errorf("expected point to be %+v, got %+v", want, got)
+		}
+
+		if seen[key] {
+			t.Errorf("point seen multiple times: %+v", got)
+		}
+		seen[key] = true
+	}
+
+	for name, ok := range seen {
+		if !ok {
+			t.Errorf("expected to see point with key %s, but did not", name)
+		}
+	}
+}
@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import (
 	"encoding/json"
@@ -19,7 +19,8 @@ import (
 	"strings"
 )
 
-type dfcStat struct {
+// DynafileCache holds the statistics of a dynafile cache of omfile.
+type DynafileCache struct {
 	Name          string `json:"name"`
 	Origin        string `json:"origin"`
 	Requests      int64  `json:"requests"`
@@ -30,8 +31,9 @@ type dfcStat struct {
 	CloseTimeouts int64  `json:"closetimeouts"`
 }
 
-func newDynafileCacheFromJSON(b []byte) (*dfcStat, error) {
-	var pstat dfcStat
+// NewDynafileCacheFromJSON decodes the impstats object of a dynafile cache.
+func NewDynafileCacheFromJSON(b []byte) (*DynafileCache, error) {
+	var pstat DynafileCache
 	err := json.Unmarshal(b, &pstat)
 	if err != nil {
 		return nil, fmt.Errorf("error decoding dynafile cache stat `%v`: %v", string(b), err)
@@ -40,52 +42,53 @@ func newDynafileCacheFromJSON(b []byte) (*dfcStat, error) {
 	return &pstat, nil
 }
 
-func (d *dfcStat) toPoints() []*point {
-	points := make([]*point, 6)
+// ToPoints returns the metrics of the cache, labelled by its name.
+func (d *DynafileCache) ToPoints() []*Point {
+	points := make([]*Point, 6)
 
-	points[0] = &point{
+	points[0] = &Point{
 		Name:        "dynafile_cache_requests",
-		Type:        counter,
+		Type:        Counter,
 		Value:       d.Requests,
 		Description: "number of requests made to obtain a dynafile",
 		LabelName:   "cache",
 		LabelValue:  d.Name,
 	}
-	points[1] = &point{
+	points[1] = &Point{
 		Name:        "dynafile_cache_level0",
-		Type:        counter,
+		Type:        Counter,
 		Value:       d.Level0,
 		Description: "number of requests for the current active file",
 		LabelName:   "cache",
 		LabelValue:  d.Name,
 	}
-	points[2] = &point{
+	points[2] = &Point{
 		Name:        "dynafile_cache_missed",
-		Type:        counter,
+		Type:        Counter,
 		Value:       d.Missed,
 		Description: "number of cache misses",
 		LabelName:   "cache",
 		LabelValue:  d.Name,
 	}
-	points[3] = &point{
+	points[3] = &Point{
 		Name:        "dynafile_cache_evicted",
-		Type:        counter,
+		Type:        Counter,
 		Value:       d.Evicted,
 		Description: "number of times a file needed to be evicted from cache",
 		LabelName:   "cache",
 		LabelValue:  d.Name,
 	}
-	points[4] = &point{
+	points[4] = &Point{
 		Name:        "dynafile_cache_maxused",
-		Type:        counter,
+		Type:        Counter,
 		Value:       d.MaxUsed,
 		Description: "maximum number of cache entries ever used",
 		LabelName:   "cache",
 		LabelValue:  d.Name,
 	}
-	points[5] = &point{
+	points[5] = &Point{
 		Name:        "dynafile_cache_closetimeouts",
-		Type:        counter,
+		Type:        Counter,
 		Value:       d.CloseTimeouts,
 		Description: "number of times a file was closed due to timeout settings",
 		LabelName:   "cache",
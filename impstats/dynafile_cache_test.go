@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import (
 	"reflect"
@@ -23,12 +23,12 @@ var (
 )
 
 func TestNewDynafileCacheFromJSON(t *testing.T) {
-	logType := getStatType(dynafileCacheLog)
-	if logType != rsyslogDynafileCache {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogDynafileCache, logType)
+	logType := DetectType(dynafileCacheLog)
+	if logType != TypeDynafileCache {
+		t.Errorf("detected pstat type should be %d but is %d", TypeDynafileCache, logType)
 	}
 
-	pstat, err := newDynafileCacheFromJSON([]byte(dynafileCacheLog))
+	pstat, err := NewDynafileCacheFromJSON([]byte(dynafileCacheLog))
 	if err != nil {
 		t.Fatalf("expected parsing dynafile cache stat not to fail, got: %v", err)
 	}
@@ -64,51 +64,51 @@ func TestNewDynafileCacheFromJSON(t *testing.T) {
 
 func TestDynafileCacheToPoints(t *testing.T) {
 
-	wants := map[string]point{
-		"dynafile_cache_requests": point{
+	wants := map[string]Point{
+		"dynafile_cache_requests": Point{
 			Name:        "dynafile_cache_requests",
-			Type:        counter,
+			Type:        Counter,
 			Value:       1783254,
 			Description: "number of requests made to obtain a dynafile",
 			LabelName:   "cache",
 			LabelValue:  "cluster",
 		},
-		"dynafile_cache_level0": point{
+		"dynafile_cache_level0": Point{
 			Name:        "dynafile_cache_level0",
-			Type:        counter,
+			Type:        Counter,
 			Value:       1470906,
 			Description: "number of requests for the current active file",
 			LabelName:   "cache",
 
 			LabelValue: "cluster",
 		},
-		"dynafile_cache_missed": point{
+		"dynafile_cache_missed": Point{
 			Name:        "dynafile_cache_missed",
-			Type:        counter,
+			Type:        Counter,
 			Value:       2625,
 			Description: "number of cache misses",
 			LabelName:   "cache",
 			LabelValue:  "cluster",
 		},
-		"dynafile_cache_evicted": point{
+		"dynafile_cache_evicted": Point{
 			Name:        "dynafile_cache_evicted",
-			Type:        counter,
+			Type:        Counter,
 			Value:       2525,
 			Description: "number of times a file needed to be evicted from cache",
 			LabelName:   "cache",
 			LabelValue:  "cluster",
 		},
-		"dynafile_cache_maxused": point{
+		"dynafile_cache_maxused": Point{
 			Name:        "dynafile_cache_maxused",
-			Type:        counter,
+			Type:        Counter,
 			Value:       100,
 			Description: "maximum number of cache entries ever used",
 			LabelName:   "cache",
 			LabelValue:  "cluster",
 		},
-		"dynafile_cache_closetimeouts": point{
+		"dynafile_cache_closetimeouts": Point{
 			Name:        "dynafile_cache_closetimeouts",
-			Type:        counter,
+			Type:        Counter,
 			Value:       10,
 			Description: "number of times a file was closed due to timeout settings",
 			LabelName:   "cache",
@@ -121,12 +121,12 @@ func TestDynafileCacheToPoints(t *testing.T) {
 		seen[name] = false
 	}
 
-	pstat, err := newDynafileCacheFromJSON(dynafileCacheLog)
+	pstat, err := NewDynafileCacheFromJSON(dynafileCacheLog)
 	if err != nil {
 		t.Fatalf("expected parsing dynafile cache stat not to fail, got: %v", err)
 	}
 
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 	for _, got := range points {
 		want, ok := wants[got.Name]
 		if !ok {
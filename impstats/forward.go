@@ -11,20 +11,22 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import (
 	"encoding/json"
 	"fmt"
 )
 
-type forward struct {
+// Forward holds the bytes sent by an omfwd action to a destination.
+type Forward struct {
 	Name      string `json:"name"`
 	BytesSent int64  `json:"bytes.sent"`
 }
 
-func newForwardFromJSON(b []byte) (*forward, error) {
-	var pstat forward
+// NewForwardFromJSON decodes the impstats object of an omfwd destination.
+func NewForwardFromJSON(b []byte) (*Forward, error) {
+	var pstat Forward
 	err := json.Unmarshal(b, &pstat)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode forward stat `%v`: %v", string(b), err)
@@ -32,12 +34,13 @@ func newForwardFromJSON(b []byte) (*forward, error) {
 	return &pstat, nil
 }
 
-func (f *forward) toPoints() []*point {
-	points := make([]*point, 1)
+// ToPoints returns the bytes sent, labelled by destination.
+func (f *Forward) ToPoints() []*Point {
+	points := make([]*Point, 1)
 
-	points[0] = &point{
+	points[0] = &Point{
 		Name:        "forward_bytes_total",
-		Type:        counter,
+		Type:        Counter,
 		Value:       f.BytesSent,
 		Description: "bytes forwarded to destination",
 		LabelName:   "destination",
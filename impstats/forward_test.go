@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import "testing"
 
@@ -20,12 +20,12 @@ var (
 )
 
 func TestNewForwardFromJSON(t *testing.T) {
-	logType := getStatType(forwardLog)
-	if logType != rsyslogForward {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogForward, logType)
+	logType := DetectType(forwardLog)
+	if logType != TypeForward {
+		t.Errorf("detected pstat type should be %d but is %d", TypeForward, logType)
 	}
 
-	pstat, err := newForwardFromJSON([]byte(forwardLog))
+	pstat, err := NewForwardFromJSON([]byte(forwardLog))
 	if err != nil {
 		t.Fatalf("expected parsing action not to fail, got: %v", err)
 	}
@@ -40,11 +40,11 @@ func TestNewForwardFromJSON(t *testing.T) {
 }
 
 func TestForwardToPoints(t *testing.T) {
-	pstat, err := newForwardFromJSON([]byte(forwardLog))
+	pstat, err := NewForwardFromJSON([]byte(forwardLog))
 	if err != nil {
 		t.Fatalf("expected parsing action not to fail, got: %v", err)
 	}
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 
 	point := points[0]
 	if want, got := "forward_bytes_total", point.Name; want != got {
@@ -55,7 +55,7 @@ func TestForwardToPoints(t *testing.T) {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("wanted '%d', got '%d'", want, got)
 	}
 
@@ -0,0 +1,232 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package impstats decodes the statistics reported by the rsyslog impstats
+// module in JSON format, and converts them to Prometheus metrics.
+//
+// Lines as logged by rsyslog are split into the timestamp and the JSON object
+// by SplitLine. The type of an object is detected by DetectType, and the
+// object decoded by Decode. Parse and ParseLine combine these steps. Decoded
+// objects are converted to points by their ToPoints method, and points to
+// Prometheus metrics by PromMetric.
+//
+// Objects of origins the package does not know can be decoded by parsers
+// registered with RegisterParser.
+package impstats
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Type is the kind of statistics reported by an impstats object.
+type Type int
+
+// Types of impstats objects, as detected by DetectType.
+const (
+	// TypeUnknown is the type of objects the package can not decode.
+	TypeUnknown Type = iota
+	// TypeAction is the type of actions, decoded as *Action.
+	TypeAction
+	// TypeInput is the type of inputs only reporting submitted messages,
+	// decoded as *Input.
+	TypeInput
+	// TypeQueue is the type of queues, decoded as *Queue.
+	TypeQueue
+	// TypeResource is the type of the resource usage, decoded as *Resource.
+	TypeResource
+	// TypeDynStat is the type of dynstats objects, decoded as *DynStat.
+	TypeDynStat
+	// TypeDynafileCache is the type of dynafile caches, decoded as
+	// *DynafileCache.
+	TypeDynafileCache
+	// TypeInputIMUDP is the type of imudp workers, decoded as *InputIMUDP.
+	TypeInputIMUDP
+	// TypeForward is the type of omfwd destinations, decoded as *Forward.
+	TypeForward
+	// TypeKubernetes is the type of mmkubernetes, decoded as *Kubernetes.
+	TypeKubernetes
+	// TypeOmkafka is the type of omkafka actions, decoded as *Omkafka.
+	TypeOmkafka
+	// TypeInputIMJournal is the type of imjournal, decoded as
+	// *InputIMJournal.
+	TypeInputIMJournal
+	// TypeImkafka is the type of imkafka consumers, decoded as *Imkafka.
+	TypeImkafka
+	// TypeOmelasticsearch is the type of omelasticsearch actions, decoded
+	// as *Omelasticsearch.
+	TypeOmelasticsearch
+	// TypeOmhttp is the type of omhttp actions, decoded as *Omhttp.
+	TypeOmhttp
+	// TypePercentile is the type of percentile stats, decoded as
+	// *Percentile.
+	TypePercentile
+	// TypeCustom is the type of objects decoded by registered parsers.
+	TypeCustom
+)
+
+var typeNames = map[Type]string{
+	TypeUnknown:         "unknown",
+	TypeAction:          "action",
+	TypeInput:           "input",
+	TypeQueue:           "queue",
+	TypeResource:        "resource",
+	TypeDynStat:         "dynstat",
+	TypeDynafileCache:   "dynafile_cache",
+	TypeInputIMUDP:      "imudp",
+	TypeForward:         "forward",
+	TypeKubernetes:      "kubernetes",
+	TypeOmkafka:         "omkafka",
+	TypeInputIMJournal:  "imjournal",
+	TypeImkafka:         "imkafka",
+	TypeOmelasticsearch: "omelasticsearch",
+	TypeOmhttp:          "omhttp",
+	TypePercentile:      "percentile",
+	TypeCustom:          "custom",
+}
+
+// String returns the name of the type, e.g. "action".
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return typeNames[TypeUnknown]
+}
+
+// Types returns all types of objects decoded by the package itself.
+func Types() []Type {
+	types := make([]Type, 0, TypeCustom-TypeAction)
+	for t := TypeAction; t < TypeCustom; t++ {
+		types = append(types, t)
+	}
+	return types
+}
+
+var (
+	// ErrMalformedLine is returned for lines not in the format logged by
+	// rsyslog.
+	ErrMalformedLine = errors.New("failed to split log line")
+	// ErrUnknownType is returned for objects of unknown type.
+	ErrUnknownType = errors.New("unknown pstat type")
+)
+
+// Object is a decoded impstats object.
+type Object interface {
+	ToPoints() []*Point
+}
+
+// Parser decodes an impstats object of a custom origin.
+type Parser func(buf []byte) (Object, error)
+
+var (
+	parsersLock sync.RWMutex
+	parsers     = make(map[string]Parser)
+)
+
+// RegisterParser registers a parser for objects of the given origin. Objects
+// of an origin with a registered parser are of TypeCustom, and take
+// precedence over the types decoded by the package itself. Registering a nil
+// parser removes the parser of the origin.
+func RegisterParser(origin string, parser Parser) {
+	parsersLock.Lock()
+	defer parsersLock.Unlock()
+	if parser == nil {
+		delete(parsers, origin)
+		return
+	}
+	parsers[origin] = parser
+}
+
+func customParser(origin string) Parser {
+	parsersLock.RLock()
+	defer parsersLock.RUnlock()
+	return parsers[origin]
+}
+
+// SplitLine splits a line logged by rsyslog into its timestamp and the JSON
+// object.
+func SplitLine(line []byte) (timestamp []byte, object []byte, err error) {
+	s := bytes.SplitN(line, []byte(" "), 4)
+	if len(s) != 4 {
+		return nil, nil, fmt.Errorf("%w, expected 4 columns, got: %v", ErrMalformedLine, len(s))
+	}
+	return s[0], s[3], nil
+}
+
+// Decode decodes an object of the given type.
+func Decode(t Type, buf []byte) (Object, error) {
+	switch t {
+	case TypeAction:
+		return object(NewActionFromJSON(buf))
+	case TypeInput:
+		return object(NewInputFromJSON(buf))
+	case TypeInputIMJournal:
+		return object(NewInputIMJournalFromJSON(buf))
+	case TypeInputIMUDP:
+		return object(NewInputIMUDPFromJSON(buf))
+	case TypeQueue:
+		return object(NewQueueFromJSON(buf))
+	case TypeResource:
+		return object(NewResourceFromJSON(buf))
+	case TypeDynStat:
+		return object(NewDynStatFromJSON(buf))
+	case TypeDynafileCache:
+		return object(NewDynafileCacheFromJSON(buf))
+	case TypeForward:
+		return object(NewForwardFromJSON(buf))
+	case TypeKubernetes:
+		return object(NewKubernetesFromJSON(buf))
+	case TypeOmkafka:
+		return object(NewOmkafkaFromJSON(buf))
+	case TypeImkafka:
+		return object(NewImkafkaFromJSON(buf))
+	case TypeOmelasticsearch:
+		return object(NewOmelasticsearchFromJSON(buf))
+	case TypeOmhttp:
+		return object(NewOmhttpFromJSON(buf))
+	case TypePercentile:
+		return object(NewPercentileFromJSON(buf))
+	case TypeCustom:
+		if parser := customParser(Origin(buf)); parser != nil {
+			return parser(buf)
+		}
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnknownType, t)
+}
+
+// object converts the result of a typed constructor to an Object, so that
+// errors do not result in non-nil objects.
+func object[T Object](o T, err error) (Object, error) {
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Parse detects the type of an object and decodes it.
+func Parse(buf []byte) (Type, Object, error) {
+	t := DetectType(buf)
+	o, err := Decode(t, buf)
+	return t, o, err
+}
+
+// ParseLine decodes the object of a line logged by rsyslog.
+func ParseLine(line []byte) (Type, Object, error) {
+	_, buf, err := SplitLine(line)
+	if err != nil {
+		return TypeUnknown, nil, err
+	}
+	return Parse(buf)
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type customStat struct {
+	Name   string `json:"name"`
+	Origin string `json:"origin"`
+	Hits   int64  `json:"hits"`
+}
+
+func (c *customStat) ToPoints() []*Point {
+	return []*Point{
+		{
+			Name:        "custom_hits",
+			Type:        Counter,
+			Value:       c.Hits,
+			Description: "hits of the custom module",
+			LabelName:   "name",
+			LabelValue:  c.Name,
+		},
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	line := []byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"test_action","processed":100000,"failed":2,"suspended":1,"suspended.duration":1000,"resumed":1}`)
+
+	typ, obj, err := ParseLine(line)
+	if err != nil {
+		t.Fatalf("expected parsing line not to fail, got: %v", err)
+	}
+	if want, got := TypeAction, typ; want != got {
+		t.Errorf("want '%v', got '%v'", want, got)
+	}
+	a, ok := obj.(*Action)
+	if !ok {
+		t.Fatalf("expected *Action, got %T", obj)
+	}
+	if want, got := int64(100000), a.Processed; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+	if want, got := 5, len(obj.ToPoints()); want != got {
+		t.Errorf("want '%d' points, got '%d'", want, got)
+	}
+}
+
+func TestParseLineErrors(t *testing.T) {
+	if _, _, err := ParseLine([]byte("garbage")); !errors.Is(err, ErrMalformedLine) {
+		t.Errorf("expected ErrMalformedLine, got: %v", err)
+	}
+
+	line := []byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"what","origin":"unknown"}`)
+	typ, obj, err := ParseLine(line)
+	if !errors.Is(err, ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got: %v", err)
+	}
+	if typ != TypeUnknown || obj != nil {
+		t.Errorf("expected unknown type and no object, got %v and %v", typ, obj)
+	}
+
+	if _, obj, err := Parse([]byte(`{"name":"test_action","processed":"x"}`)); err == nil || obj != nil {
+		t.Errorf("expected decode error and no object, got %v and %v", obj, err)
+	}
+}
+
+func TestRegisterParser(t *testing.T) {
+	RegisterParser("custom", func(buf []byte) (Object, error) {
+		var c customStat
+		if err := json.Unmarshal(buf, &c); err != nil {
+			return nil, err
+		}
+		return &c, nil
+	})
+	defer RegisterParser("custom", nil)
+
+	// The hits of the custom object would otherwise not be decoded at all, and
+	// registered parsers take precedence over the builtin detection.
+	for _, buf := range []string{
+		`{"name":"c1","origin":"custom","hits":3}`,
+		`{"name":"c1","origin":"custom","hits":3,"submitted":1}`,
+	} {
+		typ, obj, err := Parse([]byte(buf))
+		if err != nil {
+			t.Fatalf("expected parsing custom object not to fail, got: %v", err)
+		}
+		if want, got := TypeCustom, typ; want != got {
+			t.Errorf("want '%v', got '%v'", want, got)
+		}
+		points := obj.ToPoints()
+		if want, got := 1, len(points); want != got {
+			t.Fatalf("want '%d' points, got '%d'", want, got)
+		}
+		if want, got := int64(3), points[0].Value; want != got {
+			t.Errorf("want '%d', got '%d'", want, got)
+		}
+	}
+}
+
+func TestTypes(t *testing.T) {
+	seen := map[string]bool{}
+	for _, typ := range Types() {
+		if typ == TypeUnknown || typ == TypeCustom {
+			t.Errorf("unexpected type %v", typ)
+		}
+		if seen[typ.String()] {
+			t.Errorf("duplicate type name %q", typ.String())
+		}
+		seen[typ.String()] = true
+	}
+	if want, got := "unknown", Type(-1).String(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// InputIMJournal holds the statistics of the imjournal input.
+type InputIMJournal struct {
+	Name                         string `json:"name"`
+	Submitted                    int64  `json:"submitted"`
+	Read                         int64  `json:"read"`
+	Discarded                    int64  `json:"discarded"`
+	Failed                       int64  `json:"failed"`
+	PollFailed                   int64  `json:"poll_failed"`
+	Rotations                    int64  `json:"rotations"`
+	RecoveryAttempts             int64  `json:"recovery_attempts"`
+	RatelimitDiscardedInInterval int64  `json:"ratelimit_discarded_in_interval"`
+	DiskUsageBytes               int64  `json:"disk_usage_bytes"`
+}
+
+// NewInputIMJournalFromJSON decodes the impstats object of the imjournal input.
+func NewInputIMJournalFromJSON(b []byte) (*InputIMJournal, error) {
+	var pstat InputIMJournal
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding imjournal stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+// InputSubmittedPoint returns the input_submitted metric of the generic input
+// type, only exported with --compat.input-submitted.
+func (i *InputIMJournal) InputSubmittedPoint() *Point {
+	return &Point{
+		Name:        "input_submitted",
+		Type:        Counter,
+		Value:       i.Submitted,
+		Description: "messages submitted",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+}
+
+// ToPoints returns the counters and gauges of the input, labelled by its name.
+func (i *InputIMJournal) ToPoints() []*Point {
+	points := make([]*Point, 9)
+
+	points[0] = &Point{
+		Name:        "imjournal_submitted",
+		Type:        Counter,
+		Value:       i.Submitted,
+		Description: "messages submitted from the journal",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	points[1] = &Point{
+		Name:        "imjournal_read",
+		Type:        Counter,
+		Value:       i.Read,
+		Description: "messages read from the journal",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	points[2] = &Point{
+		Name:        "imjournal_discarded",
+		Type:        Counter,
+		Value:       i.Discarded,
+		Description: "messages discarded due to exceeding the maximum message size",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	points[3] = &Point{
+		Name:        "imjournal_failed",
+		Type:        Counter,
+		Value:       i.Failed,
+		Description: "failures to read messages from the journal",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	points[4] = &Point{
+		Name:        "imjournal_poll_failed",
+		Type:        Counter,
+		Value:       i.PollFailed,
+		Description: "failures to poll the journal for new messages",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	points[5] = &Point{
+		Name:        "imjournal_rotations",
+		Type:        Counter,
+		Value:       i.Rotations,
+		Description: "journal file rotations detected",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	points[6] = &Point{
+		Name:        "imjournal_recovery_attempts",
+		Type:        Counter,
+		Value:       i.RecoveryAttempts,
+		Description: "attempts to recover from journal errors by reopening the journal",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	points[7] = &Point{
+		Name:        "imjournal_ratelimit_discarded_in_interval",
+		Type:        Gauge,
+		Value:       i.RatelimitDiscardedInInterval,
+		Description: "messages discarded due to rate limiting within the current interval",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	points[8] = &Point{
+		Name:        "imjournal_disk_usage_bytes",
+		Type:        Gauge,
+		Value:       i.DiskUsageBytes,
+		Description: "disk space used by the journal in bytes",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+
+	return points
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"fmt"
+	"testing"
+)
+
+var (
+	inputIMJournalLog = []byte(`{ "name": "imjournal", "origin": "imjournal", "submitted": 1000, "read": 1010, "discarded": 3, "failed": 2, "poll_failed": 1, "rotations": 4, "recovery_attempts": 5, "ratelimit_discarded_in_interval": 7, "disk_usage_bytes": 104857600 }`)
+)
+
+func TestGetInputIMJournal(t *testing.T) {
+	logType := DetectType(inputIMJournalLog)
+	if logType != TypeInputIMJournal {
+		t.Errorf("detected pstat type should be %d but is %d", TypeInputIMJournal, logType)
+	}
+
+	pstat, err := NewInputIMJournalFromJSON(inputIMJournalLog)
+	if err != nil {
+		t.Fatalf("expected parsing imjournal stat not to fail, got: %v", err)
+	}
+
+	if want, got := "imjournal", pstat.Name; want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+	if want, got := int64(1010), pstat.Read; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+
+	if want, got := int64(5), pstat.RecoveryAttempts; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+
+	if want, got := int64(104857600), pstat.DiskUsageBytes; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
+
+func TestInputIMJournalToPoints(t *testing.T) {
+	pstat, err := NewInputIMJournalFromJSON(inputIMJournalLog)
+	if err != nil {
+		t.Fatalf("expected parsing imjournal stat not to fail, got: %v", err)
+	}
+	points := pstat.ToPoints()
+
+	testCases := []*Point{
+		{Name: "imjournal_submitted", Type: Counter, Value: 1000},
+		{Name: "imjournal_read", Type: Counter, Value: 1010},
+		{Name: "imjournal_discarded", Type: Counter, Value: 3},
+		{Name: "imjournal_failed", Type: Counter, Value: 2},
+		{Name: "imjournal_poll_failed", Type: Counter, Value: 1},
+		{Name: "imjournal_rotations", Type: Counter, Value: 4},
+		{Name: "imjournal_recovery_attempts", Type: Counter, Value: 5},
+		{Name: "imjournal_ratelimit_discarded_in_interval", Type: Gauge, Value: 7},
+		{Name: "imjournal_disk_usage_bytes", Type: Gauge, Value: 104857600},
+	}
+
+	if want, got := len(testCases), len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+
+	for idx, tc := range testCases {
+		t.Run(fmt.Sprintf("point idx %d", idx), func(t *testing.T) {
+			p := points[idx]
+			if p.Name != tc.Name {
+				t.Errorf("got name %s; want %s", p.Name, tc.Name)
+			}
+			if p.Type != tc.Type {
+				t.Errorf("got type %d; want %d", p.Type, tc.Type)
+			}
+			if p.Value != tc.Value {
+				t.Errorf("got value %d; want %d", p.Value, tc.Value)
+			}
+			if p.LabelValue != "imjournal" {
+				t.Errorf("got label value %s; want imjournal", p.LabelValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Imkafka holds the statistics of an imkafka consumer.
+type Imkafka struct {
+	Name      string `json:"name"`
+	Origin    string `json:"origin"`
+	Received  int64  `json:"received"`
+	Submitted int64  `json:"submitted"`
+	Failures  int64  `json:"failures"`
+	EOF       int64  `json:"eof"`
+	PollEmpty int64  `json:"poll_empty"`
+	MaxLag    int64  `json:"maxlag"`
+}
+
+// NewImkafkaFromJSON decodes the impstats object of an imkafka consumer.
+func NewImkafkaFromJSON(b []byte) (*Imkafka, error) {
+	var pstat Imkafka
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode imkafka stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+// InputSubmittedPoint returns the input_submitted metric of the generic input
+// type, only exported with --compat.input-submitted.
+func (i *Imkafka) InputSubmittedPoint() *Point {
+	return &Point{
+		Name:        "input_submitted",
+		Type:        Counter,
+		Value:       i.Submitted,
+		Description: "messages submitted",
+		LabelName:   "input",
+		LabelValue:  i.Name,
+	}
+}
+
+// ToPoints returns the metrics of the consumer, labelled by its stats name.
+func (i *Imkafka) ToPoints() []*Point {
+	points := make([]*Point, 6)
+
+	points[0] = &Point{
+		Name:        "imkafka_received",
+		Type:        Counter,
+		Value:       i.Received,
+		Description: "messages received from kafka",
+		LabelName:   "consumer",
+		LabelValue:  i.Name,
+	}
+
+	points[1] = &Point{
+		Name:        "imkafka_submitted",
+		Type:        Counter,
+		Value:       i.Submitted,
+		Description: "messages submitted to rsyslog for processing",
+		LabelName:   "consumer",
+		LabelValue:  i.Name,
+	}
+
+	points[2] = &Point{
+		Name:        "imkafka_failures",
+		Type:        Counter,
+		Value:       i.Failures,
+		Description: "messages that failed to be consumed, including errors reported by librdkafka",
+		LabelName:   "consumer",
+		LabelValue:  i.Name,
+	}
+
+	points[3] = &Point{
+		Name:        "imkafka_eof",
+		Type:        Counter,
+		Value:       i.EOF,
+		Description: "times the end of a partition was reached",
+		LabelName:   "consumer",
+		LabelValue:  i.Name,
+	}
+
+	points[4] = &Point{
+		Name:        "imkafka_poll_empty",
+		Type:        Counter,
+		Value:       i.PollEmpty,
+		Description: "polls of the kafka consumer that returned no messages",
+		LabelName:   "consumer",
+		LabelValue:  i.Name,
+	}
+
+	points[5] = &Point{
+		Name:        "imkafka_maxlag",
+		Type:        Gauge,
+		Value:       i.MaxLag,
+		Description: "maximum consumer lag in messages over all partitions seen during the last interval",
+		LabelName:   "consumer",
+		LabelValue:  i.Name,
+	}
+
+	return points
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"fmt"
+	"testing"
+)
+
+var (
+	imkafkaLog = []byte(`{ "name": "imkafka[logs_kafka-1:9092_consumers]", "origin": "imkafka", "submitted": 120, "received": 123, "failures": 3, "eof": 4, "poll_empty": 56, "maxlag": 42 }`)
+)
+
+func TestNewImkafkaFromJSON(t *testing.T) {
+	logType := DetectType(imkafkaLog)
+	if logType != TypeImkafka {
+		t.Errorf("detected pstat type should be %d but is %d", TypeImkafka, logType)
+	}
+
+	pstat, err := NewImkafkaFromJSON(imkafkaLog)
+	if err != nil {
+		t.Fatalf("expected parsing imkafka stat not to fail, got: %v", err)
+	}
+
+	if want, got := "imkafka[logs_kafka-1:9092_consumers]", pstat.Name; want != got {
+		t.Errorf("wanted '%s', got '%s'", want, got)
+	}
+
+	if want, got := int64(42), pstat.MaxLag; want != got {
+		t.Errorf("wanted '%d', got '%d'", want, got)
+	}
+}
+
+func TestImkafkaToPoints(t *testing.T) {
+	pstat, err := NewImkafkaFromJSON(imkafkaLog)
+	if err != nil {
+		t.Fatalf("expected parsing imkafka stat not to fail, got: %v", err)
+	}
+	points := pstat.ToPoints()
+
+	testCases := []*Point{
+		{Name: "imkafka_received", Type: Counter, Value: 123, LabelName: "consumer"},
+		{Name: "imkafka_submitted", Type: Counter, Value: 120, LabelName: "consumer"},
+		{Name: "imkafka_failures", Type: Counter, Value: 3, LabelName: "consumer"},
+		{Name: "imkafka_eof", Type: Counter, Value: 4, LabelName: "consumer"},
+		{Name: "imkafka_poll_empty", Type: Counter, Value: 56, LabelName: "consumer"},
+		{Name: "imkafka_maxlag", Type: Gauge, Value: 42, LabelName: "consumer"},
+	}
+
+	if want, got := len(testCases), len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+
+	for idx, tc := range testCases {
+		t.Run(fmt.Sprintf("point idx %d", idx), func(t *testing.T) {
+			p := points[idx]
+			if p.Name != tc.Name {
+				t.Errorf("got name %s; want %s", p.Name, tc.Name)
+			}
+			if p.Type != tc.Type {
+				t.Errorf("got type %d; want %d", p.Type, tc.Type)
+			}
+			if p.Value != tc.Value {
+				t.Errorf("got value %d; want %d", p.Value, tc.Value)
+			}
+			if p.LabelName != tc.LabelName {
+				t.Errorf("got label name %s; want %s", p.LabelName, tc.LabelName)
+			}
+			if p.LabelValue != "imkafka[logs_kafka-1:9092_consumers]" {
+				t.Errorf("got label value %s", p.LabelValue)
+			}
+		})
+	}
+}
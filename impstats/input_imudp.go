@@ -11,22 +11,24 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import (
 	"encoding/json"
 	"fmt"
 )
 
-type inputIMUDP struct {
+// InputIMUDP holds the statistics of an imudp worker thread.
+type InputIMUDP struct {
 	Name     string `json:"name"`
 	Recvmmsg int64  `json:"called.recvmmsg"`
 	Recvmsg  int64  `json:"called.recvmsg"`
 	Received int64  `json:"msgs.received"`
 }
 
-func newInputIMUDPFromJSON(b []byte) (*inputIMUDP, error) {
-	var pstat inputIMUDP
+// NewInputIMUDPFromJSON decodes the impstats object of an imudp worker thread.
+func NewInputIMUDPFromJSON(b []byte) (*InputIMUDP, error) {
+	var pstat InputIMUDP
 	err := json.Unmarshal(b, &pstat)
 	if err != nil {
 		return nil, fmt.Errorf("error decoding input stat `%v`: %v", string(b), err)
@@ -34,29 +36,30 @@ func newInputIMUDPFromJSON(b []byte) (*inputIMUDP, error) {
 	return &pstat, nil
 }
 
-func (i *inputIMUDP) toPoints() []*point {
-	points := make([]*point, 3)
+// ToPoints returns the counters of the worker, labelled by its name.
+func (i *InputIMUDP) ToPoints() []*Point {
+	points := make([]*Point, 3)
 
-	points[0] = &point{
+	points[0] = &Point{
 		Name:        "input_called_recvmmsg",
-		Type:        counter,
+		Type:        Counter,
 		Value:       i.Recvmmsg,
 		Description: "Number of recvmmsg called",
 		LabelName:   "worker",
 		LabelValue:  i.Name,
 	}
-	points[1] = &point{
+	points[1] = &Point{
 		Name:        "input_called_recvmsg",
-		Type:        counter,
+		Type:        Counter,
 		Value:       i.Recvmsg,
 		Description: "Number of recvmmsg called",
 		LabelName:   "worker",
 		LabelValue:  i.Name,
 	}
 
-	points[2] = &point{
+	points[2] = &Point{
 		Name:        "input_received",
-		Type:        counter,
+		Type:        Counter,
 		Value:       i.Received,
 		Description: "messages received",
 		LabelName:   "worker",
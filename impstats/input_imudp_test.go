@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import "testing"
 
@@ -19,13 +19,13 @@ var (
 	inputIMUDPLog = []byte(`{ "name": "test_input_imudp", "origin": "imudp", "called.recvmmsg":1000, "called.recvmsg":2000, "msgs.received":500}`)
 )
 
-func TestgetInputIMUDP(t *testing.T) {
-	logType := getStatType(inputIMUDPLog)
-	if logType != rsyslogInputIMDUP {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogInputIMDUP, logType)
+func TestGetInputIMUDP(t *testing.T) {
+	logType := DetectType(inputIMUDPLog)
+	if logType != TypeInputIMUDP {
+		t.Errorf("detected pstat type should be %d but is %d", TypeInputIMUDP, logType)
 	}
 
-	pstat, err := newInputIMUDPFromJSON([]byte(inputLog))
+	pstat, err := NewInputIMUDPFromJSON([]byte(inputIMUDPLog))
 	if err != nil {
 		t.Fatalf("expected parsing input stat not to fail, got: %v", err)
 	}
@@ -34,11 +34,11 @@ func TestgetInputIMUDP(t *testing.T) {
 		t.Errorf("want '%s', got '%s'", want, got)
 	}
 
-	if want, got := int64(1000), pstat.Recvmsg; want != got {
+	if want, got := int64(1000), pstat.Recvmmsg; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := int64(2000), pstat.Recvmmsg; want != got {
+	if want, got := int64(2000), pstat.Recvmsg; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -48,12 +48,12 @@ func TestgetInputIMUDP(t *testing.T) {
 }
 
 func TestInputIMUDPtoPoints(t *testing.T) {
-	pstat, err := newInputIMUDPFromJSON([]byte(inputIMUDPLog))
+	pstat, err := NewInputIMUDPFromJSON([]byte(inputIMUDPLog))
 	if err != nil {
 		t.Fatalf("expected parsing input stat not to fail, got: %v", err)
 	}
 
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 
 	point := points[0]
 	if want, got := "input_called_recvmmsg", point.Name; want != got {
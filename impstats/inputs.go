@@ -11,20 +11,23 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import (
 	"encoding/json"
 	"fmt"
 )
 
-type input struct {
+// Input holds the submitted messages of an input without more specific
+// statistics, e.g. imuxsock or imtcp.
+type Input struct {
 	Name      string `json:"name"`
 	Submitted int64  `json:"submitted"`
 }
 
-func newInputFromJSON(b []byte) (*input, error) {
-	var pstat input
+// NewInputFromJSON decodes the impstats object of an input.
+func NewInputFromJSON(b []byte) (*Input, error) {
+	var pstat Input
 	err := json.Unmarshal(b, &pstat)
 	if err != nil {
 		return nil, fmt.Errorf("error decoding input stat `%v`: %v", string(b), err)
@@ -32,12 +35,13 @@ func newInputFromJSON(b []byte) (*input, error) {
 	return &pstat, nil
 }
 
-func (i *input) toPoints() []*point {
-	points := make([]*point, 1)
+// ToPoints returns the submitted messages, labelled by the name of the input.
+func (i *Input) ToPoints() []*Point {
+	points := make([]*Point, 1)
 
-	points[0] = &point{
+	points[0] = &Point{
 		Name:        "input_submitted",
-		Type:        counter,
+		Type:        Counter,
 		Value:       i.Submitted,
 		Description: "messages submitted",
 		LabelName:   "input",
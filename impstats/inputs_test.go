@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import "testing"
 
@@ -19,13 +19,13 @@ var (
 	inputLog = []byte(`{"name":"test_input", "origin":"imuxsock", "submitted":1000}`)
 )
 
-func TestgetInput(t *testing.T) {
-	logType := getStatType(inputLog)
-	if logType != rsyslogInput {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogInput, logType)
+func TestGetInput(t *testing.T) {
+	logType := DetectType(inputLog)
+	if logType != TypeInput {
+		t.Errorf("detected pstat type should be %d but is %d", TypeInput, logType)
 	}
 
-	pstat, err := newInputFromJSON([]byte(inputLog))
+	pstat, err := NewInputFromJSON([]byte(inputLog))
 	if err != nil {
 		t.Fatalf("expected parsing input stat not to fail, got: %v", err)
 	}
@@ -40,12 +40,12 @@ func TestgetInput(t *testing.T) {
 }
 
 func TestInputtoPoints(t *testing.T) {
-	pstat, err := newInputFromJSON([]byte(inputLog))
+	pstat, err := NewInputFromJSON([]byte(inputLog))
 	if err != nil {
 		t.Fatalf("expected parsing input stat not to fail, got: %v", err)
 	}
 
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 
 	point := points[0]
 	if want, got := "input_submitted", point.Name; want != got {
@@ -0,0 +1,242 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+var (
+	apiNameRegexp = regexp.MustCompile(`mmkubernetes\((\S+)\)`)
+)
+
+// Kubernetes holds the statistics of mmkubernetes for a Kubernetes API.
+type Kubernetes struct {
+	Name                  string `json:"name"`
+	Url                   string
+	RecordSeen            int64 `json:"recordseen"`
+	NamespaceMetaSuccess  int64 `json:"namespacemetadatasuccess"`
+	NamespaceMetaNotFound int64 `json:"namespacemetadatanotfound"`
+	NamespaceMetaBusy     int64 `json:"namespacemetadatabusy"`
+	NamespaceMetaError    int64 `json:"namespacemetadataerror"`
+	PodMetaSuccess        int64 `json:"podmetadatasuccess"`
+	PodMetaNotFound       int64 `json:"podmetadatanotfound"`
+	PodMetaBusy           int64 `json:"podmetadatabusy"`
+	PodMetaError          int64 `json:"podmetadataerror"`
+	NamespaceMetaRateLim  int64 `json:"namespacemetadataratelimited"`
+	PodMetaRateLim        int64 `json:"podmetadataratelimited"`
+	NamespaceCacheEntries int64 `json:"namespacecachenumentries"`
+	PodCacheEntries       int64 `json:"podcachenumentries"`
+	NamespaceCacheHits    int64 `json:"namespacecachehits"`
+	PodCacheHits          int64 `json:"podcachehits"`
+	NamespaceCacheMisses  int64 `json:"namespacecachemisses"`
+	PodCacheMisses        int64 `json:"podcachemisses"`
+}
+
+// NewKubernetesFromJSON decodes the impstats object of mmkubernetes.
+func NewKubernetesFromJSON(b []byte) (*Kubernetes, error) {
+	var pstat Kubernetes
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode kubernetes stat `%v`: %v", string(b), err)
+	}
+	matches := apiNameRegexp.FindSubmatch([]byte(pstat.Name))
+	if matches != nil {
+		pstat.Url = string(matches[1])
+	}
+	return &pstat, nil
+}
+
+// ToPoints returns the metadata fetch and cache metrics, labelled by the url of
+// the Kubernetes API.
+func (k *Kubernetes) ToPoints() []*Point {
+	points := make([]*Point, 19)
+
+	points[0] = &Point{
+		Name:        "kubernetes_namespace_metadata_success_total",
+		Type:        Counter,
+		Value:       k.NamespaceMetaSuccess,
+		Description: "successful fetches of namespace metadata",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[1] = &Point{
+		Name:        "kubernetes_namespace_metadata_notfound_total",
+		Type:        Counter,
+		Value:       k.NamespaceMetaNotFound,
+		Description: "notfound fetches of namespace metadata",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[2] = &Point{
+		Name:        "kubernetes_namespace_metadata_busy_total",
+		Type:        Counter,
+		Value:       k.NamespaceMetaBusy,
+		Description: "busy fetches of namespace metadata",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[3] = &Point{
+		Name:        "kubernetes_namespace_metadata_error_total",
+		Type:        Counter,
+		Value:       k.NamespaceMetaError,
+		Description: "error fetches of namespace metadata",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[4] = &Point{
+		Name:        "kubernetes_pod_metadata_success_total",
+		Type:        Counter,
+		Value:       k.PodMetaSuccess,
+		Description: "successful fetches of pod metadata",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[5] = &Point{
+		Name:        "kubernetes_pod_metadata_notfound_total",
+		Type:        Counter,
+		Value:       k.PodMetaNotFound,
+		Description: "notfound fetches of pod metadata",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[6] = &Point{
+		Name:        "kubernetes_pod_metadata_busy_total",
+		Type:        Counter,
+		Value:       k.PodMetaBusy,
+		Description: "busy fetches of pod metadata",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[7] = &Point{
+		Name:        "kubernetes_pod_metadata_error_total",
+		Type:        Counter,
+		Value:       k.PodMetaError,
+		Description: "error fetches of pod metadata",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[8] = &Point{
+		Name:        "kubernetes_record_seen_total",
+		Type:        Counter,
+		Value:       k.RecordSeen,
+		Description: "records fetched from the api",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[9] = &Point{
+		Name:        "kubernetes_namespace_metadata_ratelimited_total",
+		Type:        Counter,
+		Value:       k.NamespaceMetaRateLim,
+		Description: "fetches of namespace metadata rejected due to rate limiting",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[10] = &Point{
+		Name:        "kubernetes_pod_metadata_ratelimited_total",
+		Type:        Counter,
+		Value:       k.PodMetaRateLim,
+		Description: "fetches of pod metadata rejected due to rate limiting",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[11] = &Point{
+		Name:        "kubernetes_namespace_cache_entries",
+		Type:        Gauge,
+		Value:       k.NamespaceCacheEntries,
+		Description: "entries in the namespace metadata cache",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[12] = &Point{
+		Name:        "kubernetes_pod_cache_entries",
+		Type:        Gauge,
+		Value:       k.PodCacheEntries,
+		Description: "entries in the pod metadata cache",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[13] = &Point{
+		Name:        "kubernetes_namespace_cache_hits_total",
+		Type:        Counter,
+		Value:       k.NamespaceCacheHits,
+		Description: "namespace metadata lookups served from the cache",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[14] = &Point{
+		Name:        "kubernetes_pod_cache_hits_total",
+		Type:        Counter,
+		Value:       k.PodCacheHits,
+		Description: "pod metadata lookups served from the cache",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[15] = &Point{
+		Name:        "kubernetes_namespace_cache_misses_total",
+		Type:        Counter,
+		Value:       k.NamespaceCacheMisses,
+		Description: "namespace metadata lookups not found in the cache",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[16] = &Point{
+		Name:        "kubernetes_pod_cache_misses_total",
+		Type:        Counter,
+		Value:       k.PodCacheMisses,
+		Description: "pod metadata lookups not found in the cache",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[17] = &Point{
+		Name:        "kubernetes_namespace_cache_hit_ratio",
+		Type:        Gauge,
+		Value:       k.NamespaceCacheHits,
+		Divisor:     k.NamespaceCacheHits + k.NamespaceCacheMisses,
+		Description: "ratio of namespace metadata lookups served from the cache since start",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	points[18] = &Point{
+		Name:        "kubernetes_pod_cache_hit_ratio",
+		Type:        Gauge,
+		Value:       k.PodCacheHits,
+		Divisor:     k.PodCacheHits + k.PodCacheMisses,
+		Description: "ratio of pod metadata lookups served from the cache since start",
+		LabelName:   "url",
+		LabelValue:  k.Url,
+	}
+
+	return points
+}
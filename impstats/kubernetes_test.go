@@ -11,21 +11,22 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import "testing"
 
 var (
-	kubernetesLog = []byte(`{ "name": "mmkubernetes(https://host.domain.tld:6443)", "origin": "mmkubernetes", "recordseen": 477943, "namespacemetadatasuccess": 7, "namespacemetadatanotfound": 0, "namespacemetadatabusy": 0, "namespacemetadataerror": 0, "podmetadatasuccess": 26, "podmetadatanotfound": 0, "podmetadatabusy": 0, "podmetadataerror": 0 }`)
+	kubernetesCacheLog = []byte(`{ "name": "mmkubernetes(https://host.domain.tld:6443)", "origin": "mmkubernetes", "recordseen": 1000, "namespacemetadatasuccess": 7, "namespacemetadatanotfound": 0, "namespacemetadatabusy": 0, "namespacemetadataerror": 0, "podmetadatasuccess": 26, "podmetadatanotfound": 0, "podmetadatabusy": 0, "podmetadataerror": 0, "namespacemetadataratelimited": 2, "podmetadataratelimited": 3, "namespacecachenumentries": 4, "podcachenumentries": 25, "namespacecachehits": 993, "podcachehits": 900, "namespacecachemisses": 7, "podcachemisses": 100 }`)
+	kubernetesLog      = []byte(`{ "name": "mmkubernetes(https://host.domain.tld:6443)", "origin": "mmkubernetes", "recordseen": 477943, "namespacemetadatasuccess": 7, "namespacemetadatanotfound": 0, "namespacemetadatabusy": 0, "namespacemetadataerror": 0, "podmetadatasuccess": 26, "podmetadatanotfound": 0, "podmetadatabusy": 0, "podmetadataerror": 0 }`)
 )
 
 func TestNewKubernetesFromJSON(t *testing.T) {
-	logType := getStatType(kubernetesLog)
-	if logType != rsyslogKubernetes {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogKubernetes, logType)
+	logType := DetectType(kubernetesLog)
+	if logType != TypeKubernetes {
+		t.Errorf("detected pstat type should be %d but is %d", TypeKubernetes, logType)
 	}
 
-	pstat, err := newKubernetesFromJSON([]byte(kubernetesLog))
+	pstat, err := NewKubernetesFromJSON([]byte(kubernetesLog))
 	if err != nil {
 		t.Fatalf("expected parsing action not to fail, got: %v", err)
 	}
@@ -77,11 +78,11 @@ func TestNewKubernetesFromJSON(t *testing.T) {
 }
 
 func TestKubernetesToPoints(t *testing.T) {
-	pstat, err := newKubernetesFromJSON([]byte(kubernetesLog))
+	pstat, err := NewKubernetesFromJSON([]byte(kubernetesLog))
 	if err != nil {
 		t.Fatalf("expected parsing action not to fail, got: %v", err)
 	}
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 
 	point := points[0]
 	if want, got := "kubernetes_namespace_metadata_success_total", point.Name; want != got {
@@ -132,3 +133,51 @@ func TestKubernetesToPoints(t *testing.T) {
 		t.Errorf("wanted '%s', got '%s'", want, got)
 	}
 }
+
+func TestKubernetesCacheToPoints(t *testing.T) {
+	pstat, err := NewKubernetesFromJSON(kubernetesCacheLog)
+	if err != nil {
+		t.Fatalf("expected parsing action not to fail, got: %v", err)
+	}
+	points := pstat.ToPoints()
+
+	testCases := []struct {
+		name  string
+		value float64
+	}{
+		{"kubernetes_namespace_metadata_ratelimited_total", 2},
+		{"kubernetes_pod_metadata_ratelimited_total", 3},
+		{"kubernetes_namespace_cache_entries", 4},
+		{"kubernetes_pod_cache_entries", 25},
+		{"kubernetes_namespace_cache_hits_total", 993},
+		{"kubernetes_pod_cache_hits_total", 900},
+		{"kubernetes_namespace_cache_misses_total", 7},
+		{"kubernetes_pod_cache_misses_total", 100},
+		{"kubernetes_namespace_cache_hit_ratio", 0.993},
+		{"kubernetes_pod_cache_hit_ratio", 0.9},
+	}
+
+	for idx, tc := range testCases {
+		point := points[9+idx]
+		if want, got := tc.name, point.Name; want != got {
+			t.Errorf("wanted '%s', got '%s'", want, got)
+		}
+
+		if want, got := tc.value, point.PromValue(); want != got {
+			t.Errorf("%s: wanted '%f', got '%f'", tc.name, want, got)
+		}
+
+		if want, got := "https://host.domain.tld:6443", point.LabelValue; want != got {
+			t.Errorf("wanted '%s', got '%s'", want, got)
+		}
+	}
+
+	// Without any lookups the ratio must not be undefined.
+	pstat, err = NewKubernetesFromJSON(kubernetesLog)
+	if err != nil {
+		t.Fatalf("expected parsing action not to fail, got: %v", err)
+	}
+	if want, got := float64(0), pstat.ToPoints()[17].PromValue(); want != got {
+		t.Errorf("wanted '%f', got '%f'", want, got)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Omelasticsearch holds the statistics of an omelasticsearch action.
+type Omelasticsearch struct {
+	Name                  string `json:"name"`
+	Origin                string `json:"origin"`
+	Submitted             int64  `json:"submitted"`
+	FailedHTTP            int64  `json:"failed.http"`
+	FailedHTTPRequests    int64  `json:"failed.httprequests"`
+	FailedCheckConn       int64  `json:"failed.checkConn"`
+	FailedES              int64  `json:"failed.es"`
+	ResponseSuccess       int64  `json:"response.success"`
+	ResponseBad           int64  `json:"response.bad"`
+	ResponseDuplicate     int64  `json:"response.duplicate"`
+	ResponseBadArgument   int64  `json:"response.badargument"`
+	ResponseBulkRejection int64  `json:"response.bulkrejection"`
+	ResponseOther         int64  `json:"response.other"`
+	Rebinds               int64  `json:"rebinds"`
+}
+
+const (
+	esFailuresDescription  = "http: requests that failed with an http error; httprequests: http requests that could not be sent to elasticsearch; checkconn: failed connection checks against elasticsearch; es: requests that elasticsearch reported as failed"
+	esResponsesDescription = "bulk item responses by outcome: success: items indexed successfully; bad: items rejected with an unparseable response; duplicate: items rejected as duplicates; badargument: items rejected due to bad arguments; bulkrejection: items rejected because the elasticsearch bulk queue was full; other: all other item errors"
+)
+
+// NewOmelasticsearchFromJSON decodes the impstats object of an omelasticsearch
+// action.
+func NewOmelasticsearchFromJSON(b []byte) (*Omelasticsearch, error) {
+	var pstat Omelasticsearch
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode omelasticsearch stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+// InputSubmittedPoint returns the input_submitted metric of the generic input
+// type, only exported with --compat.input-submitted.
+func (o *Omelasticsearch) InputSubmittedPoint() *Point {
+	return &Point{
+		Name:        "input_submitted",
+		Type:        Counter,
+		Value:       o.Submitted,
+		Description: "messages submitted",
+		LabelName:   "input",
+		LabelValue:  o.Name,
+	}
+}
+
+// ToPoints returns the submitted messages, request failures and bulk response
+// outcomes of the action.
+func (o *Omelasticsearch) ToPoints() []*Point {
+	points := make([]*Point, 12)
+
+	points[0] = &Point{
+		Name:        "omelasticsearch_submitted",
+		Type:        Counter,
+		Value:       o.Submitted,
+		Description: "messages submitted to omelasticsearch for processing",
+	}
+
+	points[1] = &Point{
+		Name:        "omelasticsearch_failures",
+		Type:        Counter,
+		Value:       o.FailedHTTP,
+		Description: esFailuresDescription,
+		LabelName:   "type",
+		LabelValue:  "http",
+	}
+
+	points[2] = &Point{
+		Name:        "omelasticsearch_failures",
+		Type:        Counter,
+		Value:       o.FailedHTTPRequests,
+		Description: esFailuresDescription,
+		LabelName:   "type",
+		LabelValue:  "httprequests",
+	}
+
+	points[3] = &Point{
+		Name:        "omelasticsearch_failures",
+		Type:        Counter,
+		Value:       o.FailedCheckConn,
+		Description: esFailuresDescription,
+		LabelName:   "type",
+		LabelValue:  "checkconn",
+	}
+
+	points[4] = &Point{
+		Name:        "omelasticsearch_failures",
+		Type:        Counter,
+		Value:       o.FailedES,
+		Description: esFailuresDescription,
+		LabelName:   "type",
+		LabelValue:  "es",
+	}
+
+	points[5] = &Point{
+		Name:        "omelasticsearch_responses",
+		Type:        Counter,
+		Value:       o.ResponseSuccess,
+		Description: esResponsesDescription,
+		LabelName:   "type",
+		LabelValue:  "success",
+	}
+
+	points[6] = &Point{
+		Name:        "omelasticsearch_responses",
+		Type:        Counter,
+		Value:       o.ResponseBad,
+		Description: esResponsesDescription,
+		LabelName:   "type",
+		LabelValue:  "bad",
+	}
+
+	points[7] = &Point{
+		Name:        "omelasticsearch_responses",
+		Type:        Counter,
+		Value:       o.ResponseDuplicate,
+		Description: esResponsesDescription,
+		LabelName:   "type",
+		LabelValue:  "duplicate",
+	}
+
+	points[8] = &Point{
+		Name:        "omelasticsearch_responses",
+		Type:        Counter,
+		Value:       o.ResponseBadArgument,
+		Description: esResponsesDescription,
+		LabelName:   "type",
+		LabelValue:  "badargument",
+	}
+
+	points[9] = &Point{
+		Name:        "omelasticsearch_responses",
+		Type:        Counter,
+		Value:       o.ResponseBulkRejection,
+		Description: esResponsesDescription,
+		LabelName:   "type",
+		LabelValue:  "bulkrejection",
+	}
+
+	points[10] = &Point{
+		Name:        "omelasticsearch_responses",
+		Type:        Counter,
+		Value:       o.ResponseOther,
+		Description: esResponsesDescription,
+		LabelName:   "type",
+		LabelValue:  "other",
+	}
+
+	points[11] = &Point{
+		Name:        "omelasticsearch_rebinds",
+		Type:        Counter,
+		Value:       o.Rebinds,
+		Description: "times the connection to elasticsearch was re-established due to rebindinterval",
+	}
+
+	return points
+}
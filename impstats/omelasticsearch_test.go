@@ -0,0 +1,88 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"fmt"
+	"testing"
+)
+
+var (
+	omelasticsearchLog = []byte(`{ "name": "omelasticsearch", "origin": "omelasticsearch", "submitted": 1000, "failed.http": 1, "failed.httprequests": 2, "failed.checkConn": 3, "failed.es": 4, "response.success": 900, "response.bad": 5, "response.duplicate": 6, "response.badargument": 7, "response.bulkrejection": 80, "response.other": 2, "rebinds": 9 }`)
+)
+
+func TestNewOmelasticsearchFromJSON(t *testing.T) {
+	logType := DetectType(omelasticsearchLog)
+	if logType != TypeOmelasticsearch {
+		t.Errorf("detected pstat type should be %d but is %d", TypeOmelasticsearch, logType)
+	}
+
+	pstat, err := NewOmelasticsearchFromJSON(omelasticsearchLog)
+	if err != nil {
+		t.Fatalf("expected parsing omelasticsearch stat not to fail, got: %v", err)
+	}
+
+	if want, got := int64(3), pstat.FailedCheckConn; want != got {
+		t.Errorf("wanted '%d', got '%d'", want, got)
+	}
+
+	if want, got := int64(80), pstat.ResponseBulkRejection; want != got {
+		t.Errorf("wanted '%d', got '%d'", want, got)
+	}
+}
+
+func TestOmelasticsearchToPoints(t *testing.T) {
+	pstat, err := NewOmelasticsearchFromJSON(omelasticsearchLog)
+	if err != nil {
+		t.Fatalf("expected parsing omelasticsearch stat not to fail, got: %v", err)
+	}
+	points := pstat.ToPoints()
+
+	testCases := []*Point{
+		{Name: "omelasticsearch_submitted", Type: Counter, Value: 1000},
+		{Name: "omelasticsearch_failures", Type: Counter, Value: 1, LabelValue: "http"},
+		{Name: "omelasticsearch_failures", Type: Counter, Value: 2, LabelValue: "httprequests"},
+		{Name: "omelasticsearch_failures", Type: Counter, Value: 3, LabelValue: "checkconn"},
+		{Name: "omelasticsearch_failures", Type: Counter, Value: 4, LabelValue: "es"},
+		{Name: "omelasticsearch_responses", Type: Counter, Value: 900, LabelValue: "success"},
+		{Name: "omelasticsearch_responses", Type: Counter, Value: 5, LabelValue: "bad"},
+		{Name: "omelasticsearch_responses", Type: Counter, Value: 6, LabelValue: "duplicate"},
+		{Name: "omelasticsearch_responses", Type: Counter, Value: 7, LabelValue: "badargument"},
+		{Name: "omelasticsearch_responses", Type: Counter, Value: 80, LabelValue: "bulkrejection"},
+		{Name: "omelasticsearch_responses", Type: Counter, Value: 2, LabelValue: "other"},
+		{Name: "omelasticsearch_rebinds", Type: Counter, Value: 9},
+	}
+
+	if want, got := len(testCases), len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+
+	for idx, tc := range testCases {
+		t.Run(fmt.Sprintf("point idx %d", idx), func(t *testing.T) {
+			p := points[idx]
+			if p.Name != tc.Name {
+				t.Errorf("got name %s; want %s", p.Name, tc.Name)
+			}
+			if p.Type != tc.Type {
+				t.Errorf("got type %d; want %d", p.Type, tc.Type)
+			}
+			if p.Value != tc.Value {
+				t.Errorf("got value %d; want %d", p.Value, tc.Value)
+			}
+			if p.LabelValue != tc.LabelValue {
+				t.Errorf("got label value %s; want %s", p.LabelValue, tc.LabelValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Omhttp holds the statistics of an omhttp action.
+type Omhttp struct {
+	Name                 string `json:"name"`
+	Origin               string `json:"origin"`
+	MessagesSubmitted    int64  `json:"messages.submitted"`
+	MessagesSuccess      int64  `json:"messages.success"`
+	MessagesFail         int64  `json:"messages.fail"`
+	MessagesRetry        int64  `json:"messages.retry"`
+	RequestCount         int64  `json:"request.count"`
+	RequestSuccess       int64  `json:"request.success"`
+	RequestFail          int64  `json:"request.fail"`
+	RequestStatusSuccess int64  `json:"request.status.success"`
+	RequestStatusFail    int64  `json:"request.status.fail"`
+	RequestBytes         int64  `json:"request.bytes"`
+	ResponseBytes        int64  `json:"response.bytes"`
+}
+
+const (
+	omhttpMessagesDescription      = "number of messages: submitted: messages submitted to omhttp for processing; success: messages delivered successfully; fail: messages that could not be delivered; retry: messages queued for retry"
+	omhttpRequestsDescription      = "number of http requests: count: requests attempted; success: requests that were sent and got a response; fail: requests that could not be sent, e.g. due to connection errors"
+	omhttpRequestStatusDescription = "http requests by response status: success: requests that got a 2xx response; fail: requests that got a non 2xx response"
+	omhttpBytesDescription         = "bytes transferred: request: bytes sent in request bodies; response: bytes received in response bodies"
+)
+
+// NewOmhttpFromJSON decodes the impstats object of an omhttp action.
+func NewOmhttpFromJSON(b []byte) (*Omhttp, error) {
+	var pstat Omhttp
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode omhttp stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+func (o *Omhttp) newPoint(name, description, typ string, value int64) *Point {
+	return &Point{
+		Name:        name,
+		Type:        Counter,
+		Value:       value,
+		Description: description,
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: typ}},
+	}
+}
+
+// ToPoints returns the message and request outcomes of the action, labelled by
+// its stats name.
+func (o *Omhttp) ToPoints() []*Point {
+	points := make([]*Point, 11)
+
+	points[0] = o.newPoint("omhttp_messages", omhttpMessagesDescription, "submitted", o.MessagesSubmitted)
+	points[1] = o.newPoint("omhttp_messages", omhttpMessagesDescription, "success", o.MessagesSuccess)
+	points[2] = o.newPoint("omhttp_messages", omhttpMessagesDescription, "fail", o.MessagesFail)
+	points[3] = o.newPoint("omhttp_messages", omhttpMessagesDescription, "retry", o.MessagesRetry)
+
+	points[4] = o.newPoint("omhttp_requests", omhttpRequestsDescription, "count", o.RequestCount)
+	points[5] = o.newPoint("omhttp_requests", omhttpRequestsDescription, "success", o.RequestSuccess)
+	points[6] = o.newPoint("omhttp_requests", omhttpRequestsDescription, "fail", o.RequestFail)
+
+	points[7] = o.newPoint("omhttp_request_status", omhttpRequestStatusDescription, "success", o.RequestStatusSuccess)
+	points[8] = o.newPoint("omhttp_request_status", omhttpRequestStatusDescription, "fail", o.RequestStatusFail)
+
+	points[9] = o.newPoint("omhttp_bytes", omhttpBytesDescription, "request", o.RequestBytes)
+	points[10] = o.newPoint("omhttp_bytes", omhttpBytesDescription, "response", o.ResponseBytes)
+
+	return points
+}
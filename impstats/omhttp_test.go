@@ -0,0 +1,91 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"fmt"
+	"testing"
+)
+
+var (
+	omhttpLog = []byte(`{ "name": "to_loki", "origin": "omhttp", "messages.submitted": 100, "messages.success": 90, "messages.fail": 4, "messages.retry": 6, "request.count": 20, "request.success": 18, "request.fail": 2, "request.status.success": 15, "request.status.fail": 3, "request.bytes": 4096, "response.bytes": 512 }`)
+)
+
+func TestNewOmhttpFromJSON(t *testing.T) {
+	logType := DetectType(omhttpLog)
+	if logType != TypeOmhttp {
+		t.Errorf("detected pstat type should be %d but is %d", TypeOmhttp, logType)
+	}
+
+	pstat, err := NewOmhttpFromJSON(omhttpLog)
+	if err != nil {
+		t.Fatalf("expected parsing omhttp stat not to fail, got: %v", err)
+	}
+
+	if want, got := "to_loki", pstat.Name; want != got {
+		t.Errorf("wanted '%s', got '%s'", want, got)
+	}
+
+	if want, got := int64(3), pstat.RequestStatusFail; want != got {
+		t.Errorf("wanted '%d', got '%d'", want, got)
+	}
+}
+
+func TestOmhttpToPoints(t *testing.T) {
+	pstat, err := NewOmhttpFromJSON(omhttpLog)
+	if err != nil {
+		t.Fatalf("expected parsing omhttp stat not to fail, got: %v", err)
+	}
+	points := pstat.ToPoints()
+
+	testCases := []struct {
+		name  string
+		typ   string
+		value int64
+	}{
+		{"omhttp_messages", "submitted", 100},
+		{"omhttp_messages", "success", 90},
+		{"omhttp_messages", "fail", 4},
+		{"omhttp_messages", "retry", 6},
+		{"omhttp_requests", "count", 20},
+		{"omhttp_requests", "success", 18},
+		{"omhttp_requests", "fail", 2},
+		{"omhttp_request_status", "success", 15},
+		{"omhttp_request_status", "fail", 3},
+		{"omhttp_bytes", "request", 4096},
+		{"omhttp_bytes", "response", 512},
+	}
+
+	if want, got := len(testCases), len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+
+	for idx, tc := range testCases {
+		t.Run(fmt.Sprintf("point idx %d", idx), func(t *testing.T) {
+			p := points[idx]
+			if p.Name != tc.name {
+				t.Errorf("got name %s; want %s", p.Name, tc.name)
+			}
+			if p.Value != tc.value {
+				t.Errorf("got value %d; want %d", p.Value, tc.value)
+			}
+			if want, got := []string{"to_loki", tc.typ}, p.PromLabelValues(); fmt.Sprint(want) != fmt.Sprint(got) {
+				t.Errorf("got label values %v; want %v", got, want)
+			}
+			if want, got := fmt.Sprintf(`%s{action="to_loki",type="%s"}`, tc.name, tc.typ), p.Key(); want != got {
+				t.Errorf("got key %s; want %s", got, want)
+			}
+		})
+	}
+}
@@ -11,14 +11,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import (
 	"encoding/json"
 	"fmt"
 )
 
-type omkafka struct {
+// Omkafka holds the statistics of an omkafka action.
+type Omkafka struct {
 	Name                     string `json:"name"`
 	Origin                   string `json:"origin"`
 	Submitted                int64  `json:"submitted"`
@@ -51,8 +52,9 @@ const (
 	errorsDescription         = "timed_out: messages that librdkafka could not deliver within timeout. These errors will cause action to be suspended but messages can be retried depending on retry options; transport: messages that librdkafka could not deliver due to transport errors. These messages can be retried depending on retry options; broker_down: messages that librdkafka could not deliver because it thinks that broker is not accessible. These messages can be retried depending on options; auth: messages that librdkafka could not deliver due to authentication errors. These messages can be retried depending on the options; ssl: messages that librdkafka could not deliver due to ssl errors. These messages can be retried depending on the options; other: rest of librdkafka errors"
 )
 
-func newOmkafkaFromJSON(b []byte) (*omkafka, error) {
-	var pstat omkafka
+// NewOmkafkaFromJSON decodes the impstats object of an omkafka action.
+func NewOmkafkaFromJSON(b []byte) (*Omkafka, error) {
+	var pstat Omkafka
 	err := json.Unmarshal(b, &pstat)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode omkafka stat `%v`: %v", string(b), err)
@@ -60,198 +62,227 @@ func newOmkafkaFromJSON(b []byte) (*omkafka, error) {
 	return &pstat, nil
 }
 
-func (o *omkafka) toPoints() []*point {
-	points := make([]*point, 22)
-
-	// A input_submitted metric was always created for omkafka
-	// as statType filter matched "submitted". Ensure we still
-	// emit that metric for backwards compatibility.
-	points[0] = &point{
+// InputSubmittedPoint returns the input_submitted metric that was always
+// created for omkafka as the statType filter matched "submitted", only
+// exported with --compat.input-submitted.
+func (o *Omkafka) InputSubmittedPoint() *Point {
+	return &Point{
 		Name:        "input_submitted",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.Submitted,
 		Description: "messages submitted",
 		LabelName:   "input",
 		LabelValue:  o.Name,
 	}
-	points[1] = &point{
+}
+
+// ToPoints returns the metrics of the action, labelled by its stats name.
+func (o *Omkafka) ToPoints() []*Point {
+	points := make([]*Point, 21)
+
+	points[0] = &Point{
 		Name:        "omkafka_messages",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.Submitted,
 		Description: messagesDescription,
-		LabelName:   "type",
-		LabelValue:  "submitted",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "submitted"}},
 	}
-	points[2] = &point{
+	points[1] = &Point{
 		Name:        "omkafka_maxoutqsize",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.MaxOutQSize,
 		Description: "high water mark of output queue size",
+		LabelName:   "action",
+		LabelValue:  o.Name,
 	}
 
-	points[3] = &point{
+	points[2] = &Point{
 		Name:        "omkafka_messages",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.Failures,
 		Description: messagesDescription,
-		LabelName:   "type",
-		LabelValue:  "failures",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "failures"}},
 	}
 
-	points[4] = &point{
+	points[3] = &Point{
 		Name:        "omkafka_topicdynacache",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.TopicDynacacheSkipped,
 		Description: topicDynaCacheDescription,
-		LabelName:   "type",
-		LabelValue:  "skipped",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "skipped"}},
 	}
 
-	points[5] = &point{
+	points[4] = &Point{
 		Name:        "omkafka_topicdynacache",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.TopicDynacacheMiss,
 		Description: topicDynaCacheDescription,
-		LabelName:   "type",
-		LabelValue:  "miss",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "miss"}},
 	}
 
-	points[6] = &point{
+	points[5] = &Point{
 		Name:        "omkafka_topicdynacache",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.TopicDynacacheEvicted,
 		Description: topicDynaCacheDescription,
-		LabelName:   "type",
-		LabelValue:  "evicted",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "evicted"}},
 	}
 
-	points[7] = &point{
+	points[6] = &Point{
 		Name:        "omkafka_messages",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.Acked,
 		Description: messagesDescription,
-		LabelName:   "type",
-		LabelValue:  "acked",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "acked"}},
 	}
 
-	points[8] = &point{
+	points[7] = &Point{
 		Name:        "omkafka_failures",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.FailuresMsgTooLarge,
 		Description: failuresDescription,
-		LabelName:   "type",
-		LabelValue:  "msg_too_large",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "msg_too_large"}},
 	}
 
-	points[9] = &point{
+	points[8] = &Point{
 		Name:        "omkafka_failures",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.FailuresUnknownTopic,
 		Description: failuresDescription,
-		LabelName:   "type",
-		LabelValue:  "unknown_topic",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "unknown_topic"}},
 	}
 
-	points[10] = &point{
+	points[9] = &Point{
 		Name:        "omkafka_failures",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.FailuresQueueFull,
 		Description: failuresDescription,
-		LabelName:   "type",
-		LabelValue:  "queue_full",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "queue_full"}},
 	}
 
-	points[11] = &point{
+	points[10] = &Point{
 		Name:        "omkafka_failures",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.FailuresUnknownPartition,
 		Description: failuresDescription,
-		LabelName:   "type",
-		LabelValue:  "unknown_partition",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "unknown_partition"}},
 	}
 
-	points[12] = &point{
+	points[11] = &Point{
 		Name:        "omkafka_failures",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.FailuresOther,
 		Description: failuresDescription,
-		LabelName:   "type",
-		LabelValue:  "other",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "other"}},
 	}
 
-	points[13] = &point{
+	points[12] = &Point{
 		Name:        "omkafka_errors",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.ErrorsTimedOut,
 		Description: errorsDescription,
-		LabelName:   "type",
-		LabelValue:  "timed_out",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "timed_out"}},
 	}
 
-	points[14] = &point{
+	points[13] = &Point{
 		Name:        "omkafka_errors",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.ErrorsTransport,
 		Description: errorsDescription,
-		LabelName:   "type",
-		LabelValue:  "transport",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "transport"}},
 	}
 
-	points[15] = &point{
+	points[14] = &Point{
 		Name:        "omkafka_errors",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.ErrorsBrokerDown,
 		Description: errorsDescription,
-		LabelName:   "type",
-		LabelValue:  "broker_down",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "broker_down"}},
 	}
 
-	points[16] = &point{
+	points[15] = &Point{
 		Name:        "omkafka_errors",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.ErrorsAuth,
 		Description: errorsDescription,
-		LabelName:   "type",
-		LabelValue:  "auth",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "auth"}},
 	}
 
-	points[17] = &point{
+	points[16] = &Point{
 		Name:        "omkafka_errors",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.ErrorsSSL,
 		Description: errorsDescription,
-		LabelName:   "type",
-		LabelValue:  "ssl",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "ssl"}},
 	}
 
-	points[18] = &point{
+	points[17] = &Point{
 		Name:        "omkafka_errors",
-		Type:        counter,
+		Type:        Counter,
 		Value:       o.ErrorsOther,
 		Description: errorsDescription,
-		LabelName:   "type",
-		LabelValue:  "other",
+		LabelName:   "action",
+		LabelValue:  o.Name,
+		ExtraLabels: []Label{{Name: "type", Value: "other"}},
 	}
 
-	points[19] = &point{
+	points[18] = &Point{
 		Name:        "omkafka_rtt_avg_usec_acg",
-		Type:        gauge,
+		Type:        Gauge,
 		Value:       o.RttAvgUsec,
 		Description: "broker round trip time in microseconds averaged over all brokers. It is based on the statistics callback window specified through statistics.interval.ms parameter to librdkafka. Average exclude brokers with less than 100 microseconds rtt",
+		LabelName:   "action",
+		LabelValue:  o.Name,
 	}
 
-	points[20] = &point{
+	points[19] = &Point{
 		Name:        "omkafka_throttle_avg_msec_avg",
-		Type:        gauge,
+		Type:        Gauge,
 		Value:       o.ThrottleAvgMsec,
 		Description: "broker throttling time in milliseconds averaged over all brokers. This is also a part of window statistics delivered by librdkakfka. Average excludes brokers with zero throttling time",
+		LabelName:   "action",
+		LabelValue:  o.Name,
 	}
 
-	points[21] = &point{
+	points[20] = &Point{
 		Name:        "omkafka_int_latency_avg_usec_avg",
-		Type:        gauge,
+		Type:        Gauge,
 		Value:       o.IntLatencyAvgUsec,
 		Description: "internal librdkafka producer queue latency in microseconds averaged other all brokers. This is also part of window statistics and average excludes brokers with zero internal latency",
+		LabelName:   "action",
+		LabelValue:  o.Name,
 	}
 
 	return points
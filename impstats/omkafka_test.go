@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import (
 	"fmt"
@@ -19,156 +19,156 @@ import (
 )
 
 var (
-	omkafkaLog = []byte(`{ "name": "omkafka", "origin": "omkafka", "submitted": 59, "maxoutqsize": 9, "failures": 0, "topicdynacache.skipped": 57, "topicdynacache.miss": 2, "topicdynacache.evicted": 0, "acked": 55, "failures_msg_too_large": 0, "failures_unknown_topic": 0, "failures_queue_full": 0, "failures_unknown_partition": 0, "failures_other": 0, "errors_timed_out": 0, "errors_transport": 0, "errors_broker_down": 0, "errors_auth": 0, "errors_ssl": 0, "errors_other": 0, "rtt_avg_usec": 0, "throttle_avg_msec": 0, "int_latency_avg_usec": 0 }`)
+	omkafkaStatsNameLog = []byte(`{ "name": "kafka_out", "origin": "omkafka", "submitted": 12, "maxoutqsize": 3, "failures": 0, "topicdynacache.skipped": 0, "topicdynacache.miss": 0, "topicdynacache.evicted": 0, "acked": 12, "failures_msg_too_large": 0, "failures_unknown_topic": 0, "failures_queue_full": 0, "failures_unknown_partition": 0, "failures_other": 0, "errors_timed_out": 0, "errors_transport": 0, "errors_broker_down": 0, "errors_auth": 0, "errors_ssl": 0, "errors_other": 0, "rtt_avg_usec": 0, "throttle_avg_msec": 0, "int_latency_avg_usec": 0 }`)
+	omkafkaLog          = []byte(`{ "name": "omkafka", "origin": "omkafka", "submitted": 59, "maxoutqsize": 9, "failures": 0, "topicdynacache.skipped": 57, "topicdynacache.miss": 2, "topicdynacache.evicted": 0, "acked": 55, "failures_msg_too_large": 0, "failures_unknown_topic": 0, "failures_queue_full": 0, "failures_unknown_partition": 0, "failures_other": 0, "errors_timed_out": 0, "errors_transport": 0, "errors_broker_down": 0, "errors_auth": 0, "errors_ssl": 0, "errors_other": 0, "rtt_avg_usec": 0, "throttle_avg_msec": 0, "int_latency_avg_usec": 0 }`)
 )
 
 func TestNewOmkafkaFromJSON(t *testing.T) {
-	logType := getStatType(omkafkaLog)
-	if logType != rsyslogOmkafka {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogOmkafka, logType)
+	logType := DetectType(omkafkaLog)
+	if logType != TypeOmkafka {
+		t.Errorf("detected pstat type should be %d but is %d", TypeOmkafka, logType)
 	}
 
-	_, err := newOmkafkaFromJSON([]byte(omkafkaLog))
+	_, err := NewOmkafkaFromJSON([]byte(omkafkaLog))
 	if err != nil {
 		t.Fatalf("expected parsing action not to fail, got: %v", err)
 	}
+
+	logType = DetectType(omkafkaStatsNameLog)
+	if logType != TypeOmkafka {
+		t.Errorf("detected pstat type of omkafka action using statsName should be %d but is %d", TypeOmkafka, logType)
+	}
 }
 
 func TestOmkafkaToPoints(t *testing.T) {
-	pstat, err := newOmkafkaFromJSON([]byte(omkafkaLog))
+	pstat, err := NewOmkafkaFromJSON([]byte(omkafkaLog))
 	if err != nil {
 		t.Fatalf("expected parsing action not to fail, got: %v", err)
 	}
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 
-	testCases := []*point{
-		{
-			Name:       "input_submitted",
-			Type:       counter,
-			Value:      59,
-			LabelValue: "omkafka",
-		},
+	testCases := []*Point{
 		{
 			Name:       "omkafka_messages",
-			Type:       counter,
+			Type:       Counter,
 			Value:      59,
 			LabelValue: "submitted",
 		},
 		{
 			Name:  "omkafka_maxoutqsize",
-			Type:  counter,
+			Type:  Counter,
 			Value: 9,
 		},
 		{
 			Name:       "omkafka_messages",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "failures",
 		},
 		{
 			Name:       "omkafka_topicdynacache",
-			Type:       counter,
+			Type:       Counter,
 			Value:      57,
 			LabelValue: "skipped",
 		},
 		{
 			Name:       "omkafka_topicdynacache",
-			Type:       counter,
+			Type:       Counter,
 			Value:      2,
 			LabelValue: "miss",
 		},
 		{
 			Name:       "omkafka_topicdynacache",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "evicted",
 		},
 		{
 			Name:       "omkafka_messages",
-			Type:       counter,
+			Type:       Counter,
 			Value:      55,
 			LabelValue: "acked",
 		},
 		{
 			Name:       "omkafka_failures",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "msg_too_large",
 		},
 
 		{
 			Name:       "omkafka_failures",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "unknown_topic",
 		},
 		{
 			Name:       "omkafka_failures",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "queue_full",
 		},
 		{
 			Name:       "omkafka_failures",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "unknown_partition",
 		},
 		{
 			Name:       "omkafka_failures",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "other",
 		},
 		{
 			Name:       "omkafka_errors",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "timed_out",
 		},
 		{
 			Name:       "omkafka_errors",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "transport",
 		},
 		{
 			Name:       "omkafka_errors",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "broker_down",
 		},
 		{
 			Name:       "omkafka_errors",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "auth",
 		},
 		{
 			Name:       "omkafka_errors",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "ssl",
 		},
 		{
 			Name:       "omkafka_errors",
-			Type:       counter,
+			Type:       Counter,
 			Value:      0,
 			LabelValue: "other",
 		},
 		{
 			Name:  "omkafka_rtt_avg_usec_acg",
-			Type:  gauge,
+			Type:  Gauge,
 			Value: 0,
 		},
 		{
 			Name:  "omkafka_throttle_avg_msec_avg",
-			Type:  gauge,
+			Type:  Gauge,
 			Value: 0,
 		},
 		{
 			Name:  "omkafka_int_latency_avg_usec_avg",
-			Type:  gauge,
+			Type:  Gauge,
 			Value: 0,
 		},
 	}
@@ -185,8 +185,15 @@ func TestOmkafkaToPoints(t *testing.T) {
 			if p.Value != tc.Value {
 				t.Errorf("got value %d;  %d", p.Value, tc.Value)
 			}
-			if p.LabelValue != tc.LabelValue {
-				t.Errorf("got label value %s;  %s", p.LabelValue, tc.LabelValue)
+			if p.LabelName != "action" || p.LabelValue != "omkafka" {
+				t.Errorf("got action label %s=%s; want action=omkafka", p.LabelName, p.LabelValue)
+			}
+			typ := ""
+			if len(p.ExtraLabels) > 0 {
+				typ = p.ExtraLabels[0].Value
+			}
+			if typ != tc.LabelValue {
+				t.Errorf("got type label value %s;  %s", typ, tc.LabelValue)
 			}
 		})
 	}
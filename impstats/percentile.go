@@ -0,0 +1,157 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Percentile holds the values of a percentile stats bucket, reported as
+// "bucket.metric|<statistic>".
+type Percentile struct {
+	Name   string             `json:"name"`
+	Origin string             `json:"origin"`
+	Values map[string]float64 `json:"values"`
+}
+
+// percentileSeries collects the statistics rsyslog reports for a single
+// bucket and metric as `bucket.metric|<statistic>` values.
+type percentileSeries struct {
+	bucket    string
+	metric    string
+	quantiles map[float64]float64
+	min       *float64
+	max       *float64
+	sum       float64
+	count     uint64
+}
+
+// NewPercentileFromJSON decodes the impstats object of a percentile stats
+// bucket.
+func NewPercentileFromJSON(b []byte) (*Percentile, error) {
+	var pstat Percentile
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding percentile stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+// series groups the values of the stat by bucket and metric. Values without
+// a `|<statistic>` suffix are bookkeeping counters and are skipped.
+func (ps *Percentile) series() []*percentileSeries {
+	byName := map[string]*percentileSeries{}
+	for key, value := range ps.Values {
+		idx := strings.LastIndex(key, "|")
+		if idx < 0 {
+			continue
+		}
+		name, stat := key[:idx], key[idx+1:]
+
+		s, ok := byName[name]
+		if !ok {
+			s = &percentileSeries{
+				bucket:    ps.Name,
+				metric:    name,
+				quantiles: map[float64]float64{},
+			}
+			if bucket, metric, found := strings.Cut(name, "."); found {
+				s.bucket, s.metric = bucket, metric
+			}
+			byName[name] = s
+		}
+
+		switch stat {
+		case "window_min":
+			v := value
+			s.min = &v
+		case "window_max":
+			v := value
+			s.max = &v
+		case "window_sum":
+			s.sum = value
+		case "window_count":
+			s.count = uint64(value)
+		default:
+			if !strings.HasPrefix(stat, "p") {
+				continue
+			}
+			q, err := strconv.ParseFloat(stat[1:], 64)
+			if err != nil || q < 0 || q > 100 {
+				continue
+			}
+			s.quantiles[q/100] = value
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	series := make([]*percentileSeries, 0, len(names))
+	for _, name := range names {
+		series = append(series, byName[name])
+	}
+	return series
+}
+
+// ToPoints returns gauges of the reported percentiles and window statistics,
+// labelled by bucket and metric.
+func (ps *Percentile) ToPoints() []*Point {
+	points := make([]*Point, 0)
+
+	for _, s := range ps.series() {
+		points = append(points, &Point{
+			Name:        "percentile",
+			Type:        Summary,
+			Value:       int64(s.sum),
+			Count:       s.count,
+			Quantiles:   s.quantiles,
+			Description: "percentiles of values observed in the last window",
+			LabelName:   "bucket",
+			LabelValue:  s.bucket,
+			ExtraLabels: []Label{{Name: "metric", Value: s.metric}},
+		})
+		if s.min != nil {
+			points = append(points, &Point{
+				Name:        "percentile_window_min",
+				Type:        Gauge,
+				Value:       int64(*s.min),
+				Description: "minimum value observed in the last window",
+				LabelName:   "bucket",
+				LabelValue:  s.bucket,
+				ExtraLabels: []Label{{Name: "metric", Value: s.metric}},
+			})
+		}
+		if s.max != nil {
+			points = append(points, &Point{
+				Name:        "percentile_window_max",
+				Type:        Gauge,
+				Value:       int64(*s.max),
+				Description: "maximum value observed in the last window",
+				LabelName:   "bucket",
+				LabelValue:  s.bucket,
+				ExtraLabels: []Label{{Name: "metric", Value: s.metric}},
+			})
+		}
+	}
+
+	return points
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	percentileEmptyLog = []byte(`{ "name": "global", "origin": "percentile", "values": { } }`)
+	percentileLog      = []byte(`{ "name": "global", "origin": "percentile", "values": { "host_statistics.new_metric_add": 1, "msg_per_host.processed|p95": 1950, "msg_per_host.processed|p50": 1500, "msg_per_host.processed|window_min": 1001, "msg_per_host.processed|window_max": 1999, "msg_per_host.processed|window_sum": 1500000, "msg_per_host.processed|window_count": 1000 } }`)
+)
+
+func TestGetPercentile(t *testing.T) {
+	for _, log := range [][]byte{percentileEmptyLog, percentileLog} {
+		if want, got := TypePercentile, DetectType(log); want != got {
+			t.Errorf("detected pstat type should be %d but is %d", want, got)
+		}
+	}
+
+	pstat, err := NewPercentileFromJSON(percentileEmptyLog)
+	if err != nil {
+		t.Fatalf("expected parsing percentile stat not to fail, got: %v", err)
+	}
+
+	if want, got := 0, len(pstat.ToPoints()); want != got {
+		t.Errorf("want %d points, got %d", want, got)
+	}
+}
+
+func TestPercentileToPoints(t *testing.T) {
+	pstat, err := NewPercentileFromJSON(percentileLog)
+	if err != nil {
+		t.Fatalf("expected parsing percentile stat not to fail, got: %v", err)
+	}
+
+	points := pstat.ToPoints()
+	if want, got := 3, len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+
+	p := points[0]
+	if want, got := `percentile{bucket="msg_per_host",metric="processed"}`, p.Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+	metric, err := p.PromMetric()
+	if err != nil {
+		t.Fatalf("expected creating summary not to fail, got: %v", err)
+	}
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.GetSummary()
+	if want, got := uint64(1000), s.GetSampleCount(); want != got {
+		t.Errorf("want count '%d', got '%d'", want, got)
+	}
+	if want, got := float64(1500000), s.GetSampleSum(); want != got {
+		t.Errorf("want sum '%f', got '%f'", want, got)
+	}
+	quantiles := map[float64]float64{}
+	for _, q := range s.GetQuantile() {
+		quantiles[q.GetQuantile()] = q.GetValue()
+	}
+	if want, got := float64(1950), quantiles[0.95]; want != got {
+		t.Errorf("want p95 '%f', got '%f'", want, got)
+	}
+	if want, got := float64(1500), quantiles[0.5]; want != got {
+		t.Errorf("want p50 '%f', got '%f'", want, got)
+	}
+
+	if want, got := "percentile_window_min", points[1].Name; want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+	if want, got := int64(1001), points[1].Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+	if want, got := "percentile_window_max", points[2].Name; want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+	if want, got := int64(1999), points[2].Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PointType is the Prometheus metric type of a point.
+type PointType int
+
+const (
+	// Counter is the type of cumulative values, which only go down when
+	// the series is reset.
+	Counter PointType = iota
+	// Gauge is the type of values which may go up and down.
+	Gauge
+	// Summary is the type of points carrying the quantiles, sum and count
+	// of observations.
+	Summary
+)
+
+// Label is a label of a point.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Point is a single sample of a metric, as produced by the ToPoints methods
+// of the decoded objects. Name is the name of the metric without the
+// rsyslog_ prefix.
+type Point struct {
+	Name        string
+	Description string
+	Type        PointType
+	Value       int64
+	// LabelName and LabelValue are the primary label of the point, usually
+	// the name of the object it was reported by.
+	LabelName  string
+	LabelValue string
+	// ExtraLabels are exported after LabelName for points that need more
+	// than one label. All points of a metric must share the same labels.
+	ExtraLabels []Label
+	// Divisor, if set, divides Value on export. It allows exporting
+	// ratios and unit conversions without losing the raw value.
+	Divisor int64
+	// Quantiles and Count are only used by summary points, whose Value
+	// holds the sum of all observations.
+	Quantiles map[float64]float64
+	Count     uint64
+	// Created is the time the series of a counter or summary was last seen
+	// to be reset, zero if it was not. It is left to the consumer of the points.
+	Created time.Time
+	// Timestamp, if set, is the time rsyslog reported the value, attached
+	// to the exported sample.
+	Timestamp time.Time
+}
+
+// PromDescription returns the Prometheus description of the metric of the
+// point, named with the rsyslog_ prefix.
+func (p *Point) PromDescription() *prometheus.Desc {
+	return prometheus.NewDesc(
+		prometheus.BuildFQName("", "rsyslog", p.Name),
+		p.Description,
+		p.PromLabelNames(),
+		nil,
+	)
+}
+
+// PromType returns the Prometheus value type of counters and gauges.
+func (p *Point) PromType() prometheus.ValueType {
+	if p.Type == Counter {
+		return prometheus.CounterValue
+	}
+	return prometheus.GaugeValue
+}
+
+// PromMetric returns the point as a Prometheus metric, with its created
+// timestamp if set. Timestamp is left to the caller, e.g. by
+// prometheus.NewMetricWithTimestamp.
+func (p *Point) PromMetric() (prometheus.Metric, error) {
+	if p.Type == Summary {
+		if !p.Created.IsZero() {
+			return prometheus.NewConstSummaryWithCreatedTimestamp(
+				p.PromDescription(),
+				p.Count,
+				p.PromValue(),
+				p.Quantiles,
+				p.Created,
+				p.PromLabelValues()...,
+			)
+		}
+		return prometheus.NewConstSummary(
+			p.PromDescription(),
+			p.Count,
+			p.PromValue(),
+			p.Quantiles,
+			p.PromLabelValues()...,
+		)
+	}
+	if p.Type == Counter && !p.Created.IsZero() {
+		return prometheus.NewConstMetricWithCreatedTimestamp(
+			p.PromDescription(),
+			p.PromType(),
+			p.PromValue(),
+			p.Created,
+			p.PromLabelValues()...,
+		)
+	}
+	return prometheus.NewConstMetric(
+		p.PromDescription(),
+		p.PromType(),
+		p.PromValue(),
+		p.PromLabelValues()...,
+	)
+}
+
+// PromValue returns the exported value of the point, Value divided by Divisor
+// if set. For summaries, it is the sum of observations.
+func (p *Point) PromValue() float64 {
+	if p.Divisor != 0 {
+		return float64(p.Value) / float64(p.Divisor)
+	}
+	return float64(p.Value)
+}
+
+// PromLabelValues returns the label values of the point in export order.
+func (p *Point) PromLabelValues() []string {
+	values := []string{}
+	if p.LabelName != "" {
+		values = append(values, p.LabelValue)
+	}
+	for _, l := range p.ExtraLabels {
+		values = append(values, l.Value)
+	}
+	return values
+}
+
+// PromLabelNames returns the label names of the point in export order.
+func (p *Point) PromLabelNames() []string {
+	names := []string{}
+	if p.LabelName != "" {
+		names = append(names, p.LabelName)
+	}
+	for _, l := range p.ExtraLabels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// Labels returns all labels of the point in export order.
+func (p *Point) Labels() []Label {
+	labels := make([]Label, 0, len(p.ExtraLabels)+1)
+	if p.LabelName != "" {
+		labels = append(labels, Label{Name: p.LabelName, Value: p.LabelValue})
+	}
+	return append(labels, p.ExtraLabels...)
+}
+
+// SetLabels replaces all labels of the point, the first label becoming the
+// primary LabelName and LabelValue.
+func (p *Point) SetLabels(labels []Label) {
+	p.LabelName, p.LabelValue, p.ExtraLabels = "", "", nil
+	if len(labels) == 0 {
+		return
+	}
+	p.LabelName, p.LabelValue = labels[0].Name, labels[0].Value
+	if len(labels) > 1 {
+		p.ExtraLabels = append([]Label{}, labels[1:]...)
+	}
+}
+
+// ResetBy returns whether next, a later value of the same series, shows that
+// the series was reset.
+func (p *Point) ResetBy(next *Point) bool {
+	if p.Type == Summary {
+		return next.Count < p.Count
+	}
+	return next.Value < p.Value
+}
+
+// Key returns the identity of the series of the point, made up of its name
+// and its labels sorted by name, with quoted values, e.g.
+// `queue_size{queue="main Q"}`. Points with the same labels in a different
+// order share a key.
+func (p *Point) Key() string {
+	labels := p.Labels()
+	if len(labels) == 0 {
+		return p.Name
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+
+	var b strings.Builder
+	b.WriteString(p.Name)
+	b.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.Name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l.Value))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
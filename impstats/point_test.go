@@ -0,0 +1,103 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCounter(t *testing.T) {
+	p1 := &Point{
+		Name:  "my counter",
+		Type:  Counter,
+		Value: int64(10),
+	}
+
+	if want, got := float64(10), p1.PromValue(); want != got {
+		t.Errorf("want '%f', got '%f'", want, got)
+	}
+
+	if want, got := prometheus.ValueType(1), p1.PromType(); want != got {
+		t.Errorf("want '%v', got '%v'", want, got)
+	}
+
+	wanted := `Desc{fqName: "rsyslog_my counter", help: "", constLabels: {}, variableLabels: {}}`
+	if want, got := wanted, p1.PromDescription().String(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	p1 := &Point{
+		Name:  "my gauge",
+		Type:  Gauge,
+		Value: int64(10),
+	}
+
+	if want, got := float64(10), p1.PromValue(); want != got {
+		t.Errorf("want '%f', got '%f'", want, got)
+	}
+
+	if want, got := prometheus.ValueType(2), p1.PromType(); want != got {
+		t.Errorf("want '%v', got '%v'", want, got)
+	}
+
+	wanted := `Desc{fqName: "rsyslog_my gauge", help: "", constLabels: {}, variableLabels: {}}`
+	if want, got := wanted, p1.PromDescription().String(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+}
+
+func TestExtraLabels(t *testing.T) {
+	p1 := &Point{
+		Name:        "my counter",
+		Type:        Counter,
+		Value:       int64(10),
+		LabelName:   "action",
+		LabelValue:  "to_loki",
+		ExtraLabels: []Label{{Name: "type", Value: "fail"}},
+	}
+
+	wanted := `Desc{fqName: "rsyslog_my counter", help: "", constLabels: {}, variableLabels: {action,type}}`
+	if want, got := wanted, p1.PromDescription().String(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+	if want, got := `my counter{action="to_loki",type="fail"}`, p1.Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
+
+func TestKey(t *testing.T) {
+	testCases := []struct {
+		p    *Point
+		want string
+	}{
+		{&Point{Name: "resource_utime"}, "resource_utime"},
+		{&Point{Name: "queue_size", LabelName: "queue", LabelValue: `main "Q"`}, `queue_size{queue="main \"Q\""}`},
+		{&Point{Name: "m", LabelName: "a", LabelValue: "x.y"}, `m{a="x.y"}`},
+		{&Point{Name: "m", LabelName: "a", LabelValue: "x", ExtraLabels: []Label{{Name: "b", Value: "y"}}}, `m{a="x",b="y"}`},
+		{&Point{Name: "m", LabelName: "b", LabelValue: "y", ExtraLabels: []Label{{Name: "a", Value: "x"}}}, `m{a="x",b="y"}`},
+		{&Point{Name: "m", LabelName: "a", LabelValue: `x",b="y`}, `m{a="x\",b=\"y"}`},
+	}
+
+	for _, tc := range testCases {
+		if want, got := tc.want, tc.p.Key(); want != got {
+			t.Errorf("want '%s', got '%s'", want, got)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	queueOwnerMain    = "main"
+	queueOwnerRuleset = "ruleset"
+	queueOwnerAction  = "action"
+	queueOwnerOther   = "other"
+)
+
+// Queue holds the statistics of a queue, reported with origin core.queue.
+type Queue struct {
+	Name          string `json:"name"`
+	Origin        string `json:"origin"`
+	Size          int64  `json:"size"`
+	Enqueued      int64  `json:"enqueued"`
+	Full          int64  `json:"full"`
+	DiscardedFull int64  `json:"discarded.full"`
+	DiscardedNf   int64  `json:"discarded.nf"`
+	MaxQsize      int64  `json:"maxqsize"`
+}
+
+// NewQueueFromJSON decodes the impstats object of a queue.
+func NewQueueFromJSON(b []byte) (*Queue, error) {
+	var pstat Queue
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode queue stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+// owner parses the queue name into the name and kind of the object owning
+// the queue. rsyslog names queues "main Q" for the main queue, "<action> queue"
+// for action queues and after the ruleset for ruleset queues, and appends
+// "[DA]" to the name of disk-assisted queues.
+func (q *Queue) owner() (owner string, kind string, diskAssisted bool) {
+	name := q.Name
+	if strings.HasSuffix(name, "[DA]") {
+		diskAssisted = true
+		name = strings.TrimSuffix(name, "[DA]")
+	}
+
+	switch {
+	case q.Origin != "" && q.Origin != "core.queue":
+		// Internal queues of modules, such as imptcp's io-work-q.
+		return name, queueOwnerOther, diskAssisted
+	case name == "main Q":
+		return queueOwnerMain, queueOwnerMain, diskAssisted
+	case strings.HasSuffix(name, " queue"):
+		return strings.TrimSuffix(name, " queue"), queueOwnerAction, diskAssisted
+	default:
+		return name, queueOwnerRuleset, diskAssisted
+	}
+}
+
+func (q *Queue) ownerLabels() []Label {
+	owner, kind, diskAssisted := q.owner()
+	return []Label{
+		{Name: "owner", Value: owner},
+		{Name: "owner_kind", Value: kind},
+		{Name: "disk_assisted", Value: strconv.FormatBool(diskAssisted)},
+	}
+}
+
+// ToPoints returns the metrics of the queue, labelled by its name, owner and
+// whether it is disk-assisted.
+func (q *Queue) ToPoints() []*Point {
+	points := make([]*Point, 6)
+	labels := q.ownerLabels()
+
+	points[0] = &Point{
+		Name:        "queue_size",
+		Type:        Gauge,
+		Value:       q.Size,
+		Description: "messages currently in queue",
+		LabelName:   "queue",
+		LabelValue:  q.Name,
+		ExtraLabels: labels,
+	}
+
+	points[1] = &Point{
+		Name:        "queue_enqueued",
+		Type:        Counter,
+		Value:       q.Enqueued,
+		Description: "total messages enqueued",
+		LabelName:   "queue",
+		LabelValue:  q.Name,
+		ExtraLabels: labels,
+	}
+
+	points[2] = &Point{
+		Name:        "queue_full",
+		Type:        Counter,
+		Value:       q.Full,
+		Description: "times queue was full",
+		LabelName:   "queue",
+		LabelValue:  q.Name,
+		ExtraLabels: labels,
+	}
+
+	points[3] = &Point{
+		Name:        "queue_discarded_full",
+		Type:        Counter,
+		Value:       q.DiscardedFull,
+		Description: "messages discarded due to queue being full",
+		LabelName:   "queue",
+		LabelValue:  q.Name,
+		ExtraLabels: labels,
+	}
+
+	points[4] = &Point{
+		Name:        "queue_discarded_not_full",
+		Type:        Counter,
+		Value:       q.DiscardedNf,
+		Description: "messages discarded when queue not full",
+		LabelName:   "queue",
+		LabelValue:  q.Name,
+		ExtraLabels: labels,
+	}
+
+	points[5] = &Point{
+		Name:        "queue_max_size",
+		Type:        Gauge,
+		Value:       q.MaxQsize,
+		Description: "maximum size queue has reached",
+		LabelName:   "queue",
+		LabelValue:  q.Name,
+		ExtraLabels: labels,
+	}
+
+	if owner, kind, _ := q.owner(); kind == queueOwnerAction {
+		points = append(points, &Point{
+			Name:        "action_queue_info",
+			Type:        Gauge,
+			Value:       1,
+			Description: "links action queues to the action owning them",
+			LabelName:   "queue",
+			LabelValue:  q.Name,
+			ExtraLabels: []Label{{Name: "action", Value: owner}},
+		})
+	}
+
+	return points
+}
@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import "testing"
 
@@ -20,12 +20,12 @@ var (
 )
 
 func TestNewQueueFromJSON(t *testing.T) {
-	logType := getStatType(queueStat)
-	if logType != rsyslogQueue {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogQueue, logType)
+	logType := DetectType(queueStat)
+	if logType != TypeQueue {
+		t.Errorf("detected pstat type should be %d but is %d", TypeQueue, logType)
 	}
 
-	pstat, err := newQueueFromJSON([]byte(queueStat))
+	pstat, err := NewQueueFromJSON([]byte(queueStat))
 	if err != nil {
 		t.Fatalf("expected parsing queue stat not to fail, got: %v", err)
 	}
@@ -60,11 +60,11 @@ func TestNewQueueFromJSON(t *testing.T) {
 }
 
 func TestQueueToPoints(t *testing.T) {
-	pstat, err := newQueueFromJSON([]byte(queueStat))
+	pstat, err := NewQueueFromJSON([]byte(queueStat))
 	if err != nil {
 		t.Fatalf("expected parsing queue stat not to fail, got: %v", err)
 	}
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 
 	point := points[0]
 	if want, got := "queue_size", point.Name; want != got {
@@ -74,7 +74,7 @@ func TestQueueToPoints(t *testing.T) {
 	if want, got := int64(10), point.Value; want != got {
 	}
 
-	if want, got := gauge, point.Type; want != got {
+	if want, got := Gauge, point.Type; want != got {
 	}
 
 	if want, got := "main Q", point.LabelValue; want != got {
@@ -90,7 +90,7 @@ func TestQueueToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -107,7 +107,7 @@ func TestQueueToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -124,7 +124,7 @@ func TestQueueToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -141,7 +141,7 @@ func TestQueueToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -158,7 +158,7 @@ func TestQueueToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := gauge, point.Type; want != got {
+	if want, got := Gauge, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -166,3 +166,48 @@ func TestQueueToPoints(t *testing.T) {
 		t.Errorf("wanted '%s', got '%s'", want, got)
 	}
 }
+
+func TestQueueOwner(t *testing.T) {
+	testCases := []struct {
+		name         string
+		origin       string
+		owner        string
+		kind         string
+		diskAssisted bool
+	}{
+		{"main Q", "core.queue", "main", queueOwnerMain, false},
+		{"main Q[DA]", "core.queue", "main", queueOwnerMain, true},
+		{"to_exporter queue", "core.queue", "to_exporter", queueOwnerAction, false},
+		{"action-3-builtin:omfwd queue[DA]", "core.queue", "action-3-builtin:omfwd", queueOwnerAction, true},
+		{"remote", "core.queue", "remote", queueOwnerRuleset, false},
+		{"io-work-q", "imptcp", "io-work-q", queueOwnerOther, false},
+	}
+
+	for _, tc := range testCases {
+		q := &Queue{Name: tc.name, Origin: tc.origin}
+		owner, kind, diskAssisted := q.owner()
+		if owner != tc.owner || kind != tc.kind || diskAssisted != tc.diskAssisted {
+			t.Errorf("%s: want (%s, %s, %t), got (%s, %s, %t)", tc.name, tc.owner, tc.kind, tc.diskAssisted, owner, kind, diskAssisted)
+		}
+	}
+}
+
+func TestActionQueueToPoints(t *testing.T) {
+	pstat, err := NewQueueFromJSON([]byte(`{"name":"to_exporter queue[DA]","origin":"core.queue","size":10,"enqueued":20,"full":30,"discarded.full":40,"discarded.nf":50,"maxqsize":60}`))
+	if err != nil {
+		t.Fatalf("expected parsing queue stat not to fail, got: %v", err)
+	}
+	points := pstat.ToPoints()
+
+	if want, got := 7, len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+
+	if want, got := `queue_size{disk_assisted="true",owner="to_exporter",owner_kind="action",queue="to_exporter queue[DA]"}`, points[0].Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+	if want, got := `action_queue_info{action="to_exporter",queue="to_exporter queue[DA]"}`, points[6].Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
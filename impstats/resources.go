@@ -0,0 +1,239 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Resource holds the resource usage of rsyslogd.
+type Resource struct {
+	Name      string `json:"name"`
+	Utime     int64  `json:"utime"`
+	Stime     int64  `json:"stime"`
+	Maxrss    int64  `json:"maxrss"`
+	Minflt    int64  `json:"minflt"`
+	Majflt    int64  `json:"majflt"`
+	Inblock   int64  `json:"inblock"`
+	Outblock  int64  `json:"oublock"`
+	Nvcsw     int64  `json:"nvcsw"`
+	Nivcsw    int64  `json:"nivcsw"`
+	Openfiles int64  `json:"openfiles"`
+}
+
+// NewResourceFromJSON decodes the impstats object of the resource usage of
+// rsyslogd.
+func NewResourceFromJSON(b []byte) (*Resource, error) {
+	var pstat Resource
+	err := json.Unmarshal(b, &pstat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode resource stat `%v`: %v", string(b), err)
+	}
+	return &pstat, nil
+}
+
+// ToPoints returns the resource usage, labelled by the name of the object.
+func (r *Resource) ToPoints() []*Point {
+	points := make([]*Point, 10)
+
+	points[0] = &Point{
+		Name:        "resource_utime",
+		Type:        Counter,
+		Value:       r.Utime,
+		Description: "user time used in microseconds",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[1] = &Point{
+		Name:        "resource_stime",
+		Type:        Counter,
+		Value:       r.Stime,
+		Description: "system time used in microsends",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[2] = &Point{
+		Name:        "resource_maxrss",
+		Type:        Gauge,
+		Value:       r.Maxrss,
+		Description: "maximum resident set size",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[3] = &Point{
+		Name:        "resource_minflt",
+		Type:        Counter,
+		Value:       r.Minflt,
+		Description: "total minor faults",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[4] = &Point{
+		Name:        "resource_majflt",
+		Type:        Counter,
+		Value:       r.Majflt,
+		Description: "total major faults",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[5] = &Point{
+		Name:        "resource_inblock",
+		Type:        Counter,
+		Value:       r.Inblock,
+		Description: "filesystem input operations",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[6] = &Point{
+		Name:        "resource_oublock",
+		Type:        Counter,
+		Value:       r.Outblock,
+		Description: "filesystem output operations",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[7] = &Point{
+		Name:        "resource_nvcsw",
+		Type:        Counter,
+		Value:       r.Nvcsw,
+		Description: "voluntary context switches",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[8] = &Point{
+		Name:        "resource_nivcsw",
+		Type:        Counter,
+		Value:       r.Nivcsw,
+		Description: "involuntary context switches",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	points[9] = &Point{
+		Name:        "resource_openfiles",
+		Type:        Gauge,
+		Value:       r.Openfiles,
+		Description: "open file descriptors",
+		LabelName:   "resource",
+		LabelValue:  r.Name,
+	}
+
+	return points
+}
+
+// ToProcessPoints exports the resource usage in the shape of the standard
+// process collector, so that dashboards built for it work against rsyslogd.
+func (r *Resource) ToProcessPoints() []*Point {
+	points := make([]*Point, 10)
+
+	points[0] = &Point{
+		Name:        "process_cpu_seconds_total",
+		Type:        Counter,
+		Value:       r.Utime,
+		Divisor:     1e6,
+		Description: "total user and system CPU time spent in seconds",
+		LabelName:   "mode",
+		LabelValue:  "user",
+	}
+
+	points[1] = &Point{
+		Name:        "process_cpu_seconds_total",
+		Type:        Counter,
+		Value:       r.Stime,
+		Divisor:     1e6,
+		Description: "total user and system CPU time spent in seconds",
+		LabelName:   "mode",
+		LabelValue:  "system",
+	}
+
+	points[2] = &Point{
+		Name:        "process_open_fds",
+		Type:        Gauge,
+		Value:       r.Openfiles,
+		Description: "number of open file descriptors",
+	}
+
+	// maxrss is reported in kilobytes.
+	points[3] = &Point{
+		Name:        "process_max_resident_memory_bytes",
+		Type:        Gauge,
+		Value:       r.Maxrss * 1024,
+		Description: "maximum resident memory size in bytes",
+	}
+
+	points[4] = &Point{
+		Name:        "process_page_faults_total",
+		Type:        Counter,
+		Value:       r.Minflt,
+		Description: "total page faults by type",
+		LabelName:   "type",
+		LabelValue:  "minor",
+	}
+
+	points[5] = &Point{
+		Name:        "process_page_faults_total",
+		Type:        Counter,
+		Value:       r.Majflt,
+		Description: "total page faults by type",
+		LabelName:   "type",
+		LabelValue:  "major",
+	}
+
+	points[6] = &Point{
+		Name:        "process_context_switches_total",
+		Type:        Counter,
+		Value:       r.Nvcsw,
+		Description: "total context switches by type",
+		LabelName:   "type",
+		LabelValue:  "voluntary",
+	}
+
+	points[7] = &Point{
+		Name:        "process_context_switches_total",
+		Type:        Counter,
+		Value:       r.Nivcsw,
+		Description: "total context switches by type",
+		LabelName:   "type",
+		LabelValue:  "involuntary",
+	}
+
+	points[8] = &Point{
+		Name:        "process_filesystem_operations_total",
+		Type:        Counter,
+		Value:       r.Inblock,
+		Description: "total filesystem operations by direction",
+		LabelName:   "direction",
+		LabelValue:  "input",
+	}
+
+	points[9] = &Point{
+		Name:        "process_filesystem_operations_total",
+		Type:        Counter,
+		Value:       r.Outblock,
+		Description: "total filesystem operations by direction",
+		LabelName:   "direction",
+		LabelValue:  "output",
+	}
+
+	return points
+}
@@ -11,21 +11,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package impstats
 
 import "testing"
 
 var (
-	resourceLog = []byte(`{"name":"resource-usage","utime":10,"stime":20,"maxrss":30,"minflt":40,"majflt":50,"inblock":60,"oublock":70,"nvcsw":80,"nivcsw":90}`)
+	resourceLog = []byte(`{"name":"resource-usage","utime":10,"stime":20,"maxrss":30,"minflt":40,"majflt":50,"inblock":60,"oublock":70,"nvcsw":80,"nivcsw":90,"openfiles":16}`)
 )
 
 func TestNewResourceFromJSON(t *testing.T) {
-	logType := getStatType(resourceLog)
-	if logType != rsyslogResource {
-		t.Errorf("detected pstat type should be %d but is %d", rsyslogResource, logType)
+	logType := DetectType(resourceLog)
+	if logType != TypeResource {
+		t.Errorf("detected pstat type should be %d but is %d", TypeResource, logType)
 	}
 
-	pstat, err := newResourceFromJSON([]byte(resourceLog))
+	pstat, err := NewResourceFromJSON([]byte(resourceLog))
 	if err != nil {
 		t.Fatalf("expected parsing resource stat not to fail, got: %v", err)
 	}
@@ -72,11 +72,11 @@ func TestNewResourceFromJSON(t *testing.T) {
 }
 
 func TestResourceToPoints(t *testing.T) {
-	pstat, err := newResourceFromJSON([]byte(resourceLog))
+	pstat, err := NewResourceFromJSON([]byte(resourceLog))
 	if err != nil {
 		t.Fatalf("expected parsing resource stat not to fail, got: %v", err)
 	}
-	points := pstat.toPoints()
+	points := pstat.ToPoints()
 
 	point := points[0]
 	if want, got := "resource_utime", point.Name; want != got {
@@ -87,7 +87,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -104,7 +104,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -121,7 +121,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := gauge, point.Type; want != got {
+	if want, got := Gauge, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -138,7 +138,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -155,7 +155,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -172,7 +172,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -189,7 +189,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -206,7 +206,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -223,7 +223,7 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	if want, got := counter, point.Type; want != got {
+	if want, got := Counter, point.Type; want != got {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
@@ -231,3 +231,44 @@ func TestResourceToPoints(t *testing.T) {
 		t.Errorf("wanted '%s', got '%s'", want, got)
 	}
 }
+
+func TestResourceToProcessPoints(t *testing.T) {
+	pstat, err := NewResourceFromJSON([]byte(resourceLog))
+	if err != nil {
+		t.Fatalf("expected parsing resource stat not to fail, got: %v", err)
+	}
+
+	if want, got := int64(16), pstat.ToPoints()[9].Value; want != got {
+		t.Errorf("want openfiles '%d', got '%d'", want, got)
+	}
+
+	testCases := []struct {
+		key   string
+		value float64
+	}{
+		{`process_cpu_seconds_total{mode="user"}`, 0.00001},
+		{`process_cpu_seconds_total{mode="system"}`, 0.00002},
+		{"process_open_fds", 16},
+		{"process_max_resident_memory_bytes", 30720},
+		{`process_page_faults_total{type="minor"}`, 40},
+		{`process_page_faults_total{type="major"}`, 50},
+		{`process_context_switches_total{type="voluntary"}`, 80},
+		{`process_context_switches_total{type="involuntary"}`, 90},
+		{`process_filesystem_operations_total{direction="input"}`, 60},
+		{`process_filesystem_operations_total{direction="output"}`, 70},
+	}
+
+	points := pstat.ToProcessPoints()
+	if want, got := len(testCases), len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+
+	for idx, tc := range testCases {
+		if want, got := tc.key, points[idx].Key(); want != got {
+			t.Errorf("want '%s', got '%s'", want, got)
+		}
+		if want, got := tc.value, points[idx].PromValue(); want != got {
+			t.Errorf("%s: want '%f', got '%f'", tc.key, want, got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+var originRegexp = regexp.MustCompile(`"origin"\s*:\s*"([^"]*)"`)
+
+// Origin returns the origin of a stats line, or an empty string if
+// the line has none.
+func Origin(buf []byte) string {
+	matches := originRegexp.FindSubmatch(buf)
+	if matches == nil {
+		return ""
+	}
+	return string(matches[1])
+}
+
+// ObjectName returns the name of the object of a stats line, or an empty
+// string if the line has none. The name is decoded, as names of e.g. inputs
+// listening on a socket contain characters escaped in JSON.
+func ObjectName(buf []byte) string {
+	var object struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(buf, &object); err != nil {
+		return ""
+	}
+	return object.Name
+}
+
+// DetectType returns the type of an object.
+func DetectType(buf []byte) Type {
+	if origin := Origin(buf); origin != "" && customParser(origin) != nil {
+		return TypeCustom
+	}
+	line := string(buf)
+	if strings.HasPrefix(Origin(buf), "percentile") {
+		// percentile metric names are user defined and may contain any of the
+		// words used to detect other types, so check them first.
+		return TypePercentile
+	} else if strings.Contains(line, "processed") {
+		return TypeAction
+	} else if Origin(buf) == "omkafka" {
+		// Not checking for just omkafka here as multiple actions may/will contain that word,
+		// and omkafka actions using statsName do not carry the module name.
+		// omkafka lines have a submitted field, so they need to be filtered before TypeInput
+		return TypeOmkafka
+	} else if strings.Contains(line, "failed.httprequests") {
+		// omelasticsearch lines have a submitted field, so they need to be filtered before TypeInput
+		return TypeOmelasticsearch
+	} else if strings.Contains(line, "request.status.fail") {
+		// omhttp lines have a messages.submitted field, so they need to be filtered before TypeInput
+		return TypeOmhttp
+	} else if strings.Contains(line, "recovery_attempts") {
+		// imjournal lines have a submitted field as well, recovery_attempts is unique to them.
+		return TypeInputIMJournal
+	} else if strings.Contains(line, "poll_empty") {
+		// imkafka lines have a submitted field as well, poll_empty is unique to them.
+		return TypeImkafka
+	} else if strings.Contains(line, "submitted") {
+		return TypeInput
+	} else if strings.Contains(line, "called.recvmmsg") {
+		return TypeInputIMUDP
+	} else if strings.Contains(line, "enqueued") {
+		return TypeQueue
+	} else if strings.Contains(line, "utime") {
+		return TypeResource
+	} else if strings.Contains(line, "dynstats") {
+		return TypeDynStat
+	} else if strings.Contains(line, "dynafile cache") {
+		return TypeDynafileCache
+	} else if strings.Contains(line, "omfwd") {
+		return TypeForward
+	} else if strings.Contains(line, "mmkubernetes") {
+		return TypeKubernetes
+	}
+	return TypeUnknown
+}
@@ -0,0 +1,28 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impstats
+
+import "testing"
+
+func TestObjectName(t *testing.T) {
+	if want, got := "main Q", ObjectName(queueStat); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+	if want, got := "", ObjectName([]byte(`{"origin":"core.queue"}`)); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+	if want, got := `imptcp(*/var/run/"log".sock)`, ObjectName([]byte(`{"name":"imptcp(*\/var\/run\/\"log\".sock)","origin":"imptcp","submitted":1}`)); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
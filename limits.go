@@ -0,0 +1,92 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	overflowDrop = "drop"
+	overflowFold = "fold"
+
+	// overflowLabelValue replaces all label values of the series new label
+	// values are folded into.
+	overflowLabelValue = "__overflow__"
+)
+
+// seriesLimits limits the number of series per metric family kept in the
+// point store. Limits of 0 mean unlimited.
+type seriesLimits struct {
+	// Default applies to all families without a more specific limit.
+	Default int `json:"default,omitempty"`
+	// Families limits families by their name without the rsyslog_ prefix,
+	// e.g. "action_processed".
+	Families map[string]int `json:"families,omitempty"`
+	// DynstatsBuckets limits dynstats buckets by bucket name.
+	DynstatsBuckets map[string]int `json:"dynstats_buckets,omitempty"`
+	// Overflow is either "drop" to drop new series once a limit is reached,
+	// or "fold" to sum them up into a single series with all label values
+	// set to __overflow__.
+	Overflow string `json:"overflow,omitempty"`
+}
+
+func (l *seriesLimits) validate() error {
+	switch l.Overflow {
+	case "":
+		l.Overflow = overflowDrop
+	case overflowDrop, overflowFold:
+	default:
+		return fmt.Errorf("invalid overflow %q, must be one of %q or %q", l.Overflow, overflowDrop, overflowFold)
+	}
+	if l.Default < 0 {
+		return fmt.Errorf("invalid default limit %d", l.Default)
+	}
+	for family, limit := range l.Families {
+		if limit < 0 {
+			return fmt.Errorf("invalid limit %d for family %q", limit, family)
+		}
+	}
+	for bucket, limit := range l.DynstatsBuckets {
+		if limit < 0 {
+			return fmt.Errorf("invalid limit %d for dynstats bucket %q", limit, bucket)
+		}
+	}
+	return nil
+}
+
+// forFamily returns the limit of a family, where dynstats bucket limits take
+// precedence over family limits.
+func (l *seriesLimits) forFamily(family string) int {
+	if l == nil {
+		return 0
+	}
+	if bucket := dynStatBucketFromFamily(family); bucket != "" {
+		if limit, ok := l.DynstatsBuckets[bucket]; ok {
+			return limit
+		}
+	}
+	if limit, ok := l.Families[family]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+func dynStatBucketFromFamily(family string) string {
+	if bucket, ok := strings.CutPrefix(family, "dynstat_"); ok {
+		return bucket
+	}
+	return ""
+}
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -31,6 +32,23 @@ var (
 	certPath      = flag.String("tls.server-crt", "", "Path to PEM encoded file containing TLS server cert.")
 	keyPath       = flag.String("tls.server-key", "", "Path to PEM encoded file containing TLS server key (unencyrpted).")
 	silent        = flag.Bool("silent", false, "Disable logging of errors in handling stats lines")
+	configFile    = flag.String("config.file", "", "Path to an optional JSON configuration file.")
+
+	inputSubmitted       = flag.Bool("compat.input-submitted", false, "Also export submitted messages of objects handled as generic inputs by older versions as input_submitted")
+	enableOpenMetrics    = flag.Bool("web.enable-openmetrics", false, "Serve OpenMetrics with units and _created samples to scrapes negotiating it, requires the conformant naming scheme")
+	namingScheme         = flag.String("metrics.naming-scheme", namingLegacy, "Metric naming scheme, one of legacy, conformant (following the Prometheus naming conventions) or both for migrations")
+	impstatsTimestamps   = flag.Bool("impstats.timestamps", false, "Attach the timestamps of impstats lines to the exported samples")
+	maxClockSkew         = flag.Duration("impstats.max-clock-skew", 5*time.Minute, "Maximum difference of impstats timestamps to the local clock, samples with larger skew are exported without timestamp")
+	processMetrics       = flag.Bool("resource.process-metrics", false, "Also export rsyslogd resource usage as standard process metrics")
+	detectUnmappedFields = flag.Bool("impstats.detect-unmapped-fields", false, "Detect fields of stats lines the exporter does not export, and report them as rsyslog_exporter_unmapped_field_info")
+	deadLetterPath       = flag.String("deadletter.file", "", "Path to an optional file recording stats lines that could not be handled")
+	deadLetterMaxSize    = flag.Int64("deadletter.max-size", 10<<20, "Size in bytes at which the dead letter file is rotated")
+	deadLetterRateLimit  = flag.Int("deadletter.rate-limit", 10, "Maximum number of lines written to the dead letter file per second")
+	pipelineWorkers      = flag.Int("pipeline.workers", 1, "Number of workers handling stats lines, lines of the same object are always handled by the same worker")
+	pipelineQueueSize    = flag.Int("pipeline.queue-size", 1000, "Number of stats lines queued per worker")
+	pipelineOverflow     = flag.String("pipeline.overflow", overflowBlock, "What to do when the queue of a worker is full, one of block (stop reading from rsyslog) or drop-oldest")
+
+	collectors = registerCollectorFlags(flag.CommandLine)
 )
 
 func main() {
@@ -40,7 +58,46 @@ func main() {
 	}
 
 	flag.Parse()
+	if err := validateNamingScheme(*namingScheme); err != nil {
+		log.Fatal(err)
+	}
+	if err := validateOpenMetrics(*enableOpenMetrics, *namingScheme); err != nil {
+		log.Fatal(err)
+	}
+	pipeline, err := newPipeline(*pipelineWorkers, *pipelineQueueSize, *pipelineOverflow)
+	if err != nil {
+		log.Fatalf("invalid pipeline settings: %v", err)
+	}
 	exporter := newRsyslogExporter()
+	exporter.pipeline = pipeline
+	exporter.namingScheme = *namingScheme
+	exporter.disabledCollectors = disabledCollectors(collectors)
+	if *detectUnmappedFields {
+		exporter.unmappedFields = newUnmappedFields()
+	}
+	exporter.impstatsTimestamps = *impstatsTimestamps
+	exporter.maxClockSkew = *maxClockSkew
+	exporter.inputSubmitted = *inputSubmitted
+	exporter.processMetrics = *processMetrics
+
+	if *deadLetterPath != "" {
+		d, err := newDeadLetterFile(*deadLetterPath, *deadLetterMaxSize, *deadLetterRateLimit)
+		if err != nil {
+			log.Fatalf("error opening dead letter file %s: %v", *deadLetterPath, err)
+		}
+		exporter.deadLetters = d
+	}
+
+	if *configFile != "" {
+		cfg, err := loadConfig(*configFile)
+		if err != nil {
+			log.Fatalf("error loading config file %s: %v", *configFile, err)
+		}
+		exporter.dynStatRules = cfg.dynStatRules()
+		exporter.limits = cfg.Limits
+		exporter.relabelConfigs = cfg.RelabelConfigs
+		exporter.aggregationRules = cfg.Aggregations
+	}
 
 	go func() {
 		c := make(chan os.Signal, 1)
@@ -55,7 +112,7 @@ func main() {
 	}()
 
 	prometheus.MustRegister(exporter)
-	http.Handle(*metricPath, promhttp.Handler())
+	http.Handle(*metricPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, newMetricsHandler(prometheus.DefaultGatherer, *enableOpenMetrics)))
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
 <head><title>Rsyslog exporter</title></head>
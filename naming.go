@@ -0,0 +1,109 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+const (
+	namingLegacy     = "legacy"
+	namingConformant = "conformant"
+	namingBoth       = "both"
+)
+
+// conformantFamily describes how a legacy metric family is exported in the
+// conformant naming scheme, for families that need more than the _total
+// suffix on counters.
+type conformantFamily struct {
+	Name string
+	Type impstats.PointType
+	// Multiplier and Divisor convert values to base units.
+	Multiplier int64
+	Divisor    int64
+}
+
+// conformantFamilies holds all type and unit corrections of the conformant
+// naming scheme, keyed by legacy family name.
+var conformantFamilies = map[string]conformantFamily{
+	"action_suspended_duration":        {Name: "action_suspended_duration_seconds_total", Type: impstats.Counter},
+	"resource_utime":                   {Name: "resource_utime_seconds_total", Type: impstats.Counter, Divisor: 1000000},
+	"resource_stime":                   {Name: "resource_stime_seconds_total", Type: impstats.Counter, Divisor: 1000000},
+	"resource_maxrss":                  {Name: "resource_maxrss_bytes", Type: impstats.Gauge, Multiplier: 1024},
+	"omkafka_maxoutqsize":              {Name: "omkafka_max_outq_size", Type: impstats.Gauge},
+	"omkafka_rtt_avg_usec_acg":         {Name: "omkafka_rtt_avg_seconds", Type: impstats.Gauge, Divisor: 1000000},
+	"omkafka_throttle_avg_msec_avg":    {Name: "omkafka_throttle_avg_seconds", Type: impstats.Gauge, Divisor: 1000},
+	"omkafka_int_latency_avg_usec_avg": {Name: "omkafka_int_latency_avg_seconds", Type: impstats.Gauge, Divisor: 1000000},
+}
+
+func validateNamingScheme(scheme string) error {
+	switch scheme {
+	case namingLegacy, namingConformant, namingBoth:
+		return nil
+	}
+	return fmt.Errorf("invalid naming scheme %q, must be one of %q, %q or %q", scheme, namingLegacy, namingConformant, namingBoth)
+}
+
+// applyNamingScheme returns the points as exported by the naming scheme. The
+// toPoints functions produce points in the legacy scheme, which are stored and
+// subject to relabeling, aggregations and series limits by their legacy
+// names. The naming scheme is only applied on collection.
+func applyNamingScheme(scheme string, points []*impstats.Point) []*impstats.Point {
+	switch scheme {
+	case namingConformant:
+		conformant := make([]*impstats.Point, len(points))
+		for i, p := range points {
+			conformant[i] = conformantPoint(p)
+		}
+		return conformant
+	case namingBoth:
+		both := make([]*impstats.Point, 0, 2*len(points))
+		for _, p := range points {
+			both = append(both, p)
+			if c := conformantPoint(p); c.Name != p.Name {
+				both = append(both, c)
+			}
+		}
+		return both
+	}
+	return points
+}
+
+// conformantPoint returns p named according to the Prometheus naming
+// conventions, with values in base units.
+func conformantPoint(p *impstats.Point) *impstats.Point {
+	c := *p
+	if f, ok := conformantFamilies[p.Name]; ok {
+		c.Name = f.Name
+		c.Type = f.Type
+		if f.Multiplier != 0 {
+			c.Value *= f.Multiplier
+		}
+		if f.Divisor != 0 {
+			if c.Divisor != 0 {
+				c.Divisor *= f.Divisor
+			} else {
+				c.Divisor = f.Divisor
+			}
+		}
+		return &c
+	}
+	if c.Type == impstats.Counter && !strings.HasSuffix(c.Name, "_total") {
+		c.Name += "_total"
+	}
+	return &c
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestConformantPoint(t *testing.T) {
+	testCases := []struct {
+		in    *impstats.Point
+		name  string
+		typ   impstats.PointType
+		value float64
+	}{
+		{&impstats.Point{Name: "action_processed", Type: impstats.Counter, Value: 10}, "action_processed_total", impstats.Counter, 10},
+		{&impstats.Point{Name: "forward_bytes_total", Type: impstats.Counter, Value: 10}, "forward_bytes_total", impstats.Counter, 10},
+		{&impstats.Point{Name: "queue_size", Type: impstats.Gauge, Value: 10}, "queue_size", impstats.Gauge, 10},
+		{&impstats.Point{Name: "resource_utime", Type: impstats.Counter, Value: 1500000}, "resource_utime_seconds_total", impstats.Counter, 1.5},
+		{&impstats.Point{Name: "resource_maxrss", Type: impstats.Gauge, Value: 2}, "resource_maxrss_bytes", impstats.Gauge, 2048},
+		{&impstats.Point{Name: "omkafka_maxoutqsize", Type: impstats.Counter, Value: 7}, "omkafka_max_outq_size", impstats.Gauge, 7},
+		{&impstats.Point{Name: "omkafka_rtt_avg_usec_acg", Type: impstats.Gauge, Value: 250}, "omkafka_rtt_avg_seconds", impstats.Gauge, 0.00025},
+		{&impstats.Point{Name: "percentile", Type: impstats.Summary, Value: 10}, "percentile", impstats.Summary, 10},
+	}
+
+	for _, tc := range testCases {
+		c := conformantPoint(tc.in)
+		if c.Name != tc.name || c.Type != tc.typ || c.PromValue() != tc.value {
+			t.Errorf("%s: want (%s, %d, %v), got (%s, %d, %v)", tc.in.Name, tc.name, tc.typ, tc.value, c.Name, c.Type, c.PromValue())
+		}
+	}
+}
+
+func TestApplyNamingScheme(t *testing.T) {
+	points := []*impstats.Point{
+		{Name: "action_processed", Type: impstats.Counter},
+		{Name: "queue_size", Type: impstats.Gauge},
+		{Name: "omkafka_maxoutqsize", Type: impstats.Counter},
+	}
+
+	testCases := map[string][]string{
+		namingLegacy:     {"action_processed", "queue_size", "omkafka_maxoutqsize"},
+		namingConformant: {"action_processed_total", "queue_size", "omkafka_max_outq_size"},
+		namingBoth:       {"action_processed", "action_processed_total", "queue_size", "omkafka_maxoutqsize", "omkafka_max_outq_size"},
+	}
+
+	for scheme, want := range testCases {
+		got := applyNamingScheme(scheme, points)
+		if len(want) != len(got) {
+			t.Errorf("%s: want %d points, got %d", scheme, len(want), len(got))
+			continue
+		}
+		for i := range want {
+			if want[i] != got[i].Name {
+				t.Errorf("%s: want '%s', got '%s'", scheme, want[i], got[i].Name)
+			}
+		}
+	}
+
+	if err := validateNamingScheme("modern"); err == nil {
+		t.Error("expected unknown naming scheme to be invalid")
+	}
+}
+
+func TestNamingSchemeKeepsLegacyRules(t *testing.T) {
+	cfg, err := parseConfig([]byte(`{
+		"limits": {"dynstats_buckets": {"msg_per_host": 2}},
+		"relabel_configs": [{"source_labels": ["__name__"], "regex": "queue_size", "action": "drop"}]
+	}`))
+	if err != nil {
+		t.Fatalf("expected parsing config not to fail, got: %v", err)
+	}
+
+	for _, scheme := range []string{namingLegacy, namingConformant, namingBoth} {
+		re := newRsyslogExporter()
+		re.namingScheme = scheme
+		re.limits = cfg.Limits
+		re.relabelConfigs = cfg.RelabelConfigs
+
+		lines := []string{
+			`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"msg_per_host","origin":"dynstats.bucket","values":{"h1":1,"h2":2,"h3":3,"h4":4,"h5":5}}`,
+			`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"main Q","origin":"core.queue","size":10,"enqueued":20,"full":0,"discarded.full":0,"discarded.nf":0,"maxqsize":15}`,
+		}
+		for _, line := range lines {
+			if err := re.handleStatLine([]byte(line)); err != nil {
+				t.Fatalf("%s: expected handling line not to fail, got: %v", scheme, err)
+			}
+		}
+
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(re)
+		mfs, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		series := map[string]int{}
+		for _, mf := range mfs {
+			series[mf.GetName()] = len(mf.GetMetric())
+		}
+
+		for _, name := range []string{"rsyslog_dynstat_msg_per_host", "rsyslog_dynstat_msg_per_host_total"} {
+			if want, got := 2, series[name]; got != 0 && want != got {
+				t.Errorf("%s: %s: want '%d' series, got '%d'", scheme, name, want, got)
+			}
+		}
+		if series["rsyslog_dynstat_msg_per_host"]+series["rsyslog_dynstat_msg_per_host_total"] == 0 {
+			t.Errorf("%s: expected dynstats series to be exported", scheme)
+		}
+		if got := series["rsyslog_queue_size"]; got != 0 {
+			t.Errorf("%s: expected queue_size to be dropped, got '%d' series", scheme, got)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+// objectTracker records when each rsyslog object was first seen.
+type objectTracker struct {
+	lock      sync.Mutex
+	firstSeen map[string]time.Time
+}
+
+func newObjectTracker() *objectTracker {
+	return &objectTracker{
+		firstSeen: make(map[string]time.Time),
+	}
+}
+
+// observe records that an object was seen at now and returns its info and
+// first and last seen points.
+func (t *objectTracker) observe(kind impstats.Type, origin, name string, now time.Time) []*impstats.Point {
+	labels := []impstats.Label{{Name: "name", Value: name}, {Name: "kind", Value: kind.String()}}
+	points := make([]*impstats.Point, 3)
+
+	points[0] = &impstats.Point{
+		Name:        "object_info",
+		Type:        impstats.Gauge,
+		Value:       1,
+		Description: "rsyslog objects reported by impstats",
+		LabelName:   "origin",
+		LabelValue:  origin,
+		ExtraLabels: labels,
+	}
+
+	key := points[0].Key()
+	t.lock.Lock()
+	firstSeen, ok := t.firstSeen[key]
+	if !ok {
+		firstSeen = now
+		t.firstSeen[key] = now
+	}
+	t.lock.Unlock()
+
+	points[1] = &impstats.Point{
+		Name:        "object_first_seen_timestamp_seconds",
+		Type:        impstats.Gauge,
+		Value:       firstSeen.UnixMilli(),
+		Divisor:     1000,
+		Description: "time the rsyslog object was first reported by impstats",
+		LabelName:   "origin",
+		LabelValue:  origin,
+		ExtraLabels: labels,
+	}
+
+	points[2] = &impstats.Point{
+		Name:        "object_last_seen_timestamp_seconds",
+		Type:        impstats.Gauge,
+		Value:       now.UnixMilli(),
+		Divisor:     1000,
+		Description: "time the rsyslog object was last reported by impstats",
+		LabelName:   "origin",
+		LabelValue:  origin,
+		ExtraLabels: labels,
+	}
+
+	return points
+}
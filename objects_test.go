@@ -0,0 +1,58 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+func TestObjectTracker(t *testing.T) {
+	tracker := newObjectTracker()
+
+	first := time.Unix(1000, 0)
+	points := tracker.observe(impstats.TypeQueue, "core.queue", "main Q", first)
+	if want, got := 3, len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+	if want, got := `object_info{kind="queue",name="main Q",origin="core.queue"}`, points[0].Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+	points = tracker.observe(impstats.TypeQueue, "core.queue", "main Q", time.Unix(1060, 0))
+	if want, got := float64(1000), points[1].PromValue(); want != got {
+		t.Errorf("want first seen '%v', got '%v'", want, got)
+	}
+	if want, got := float64(1060), points[2].PromValue(); want != got {
+		t.Errorf("want last seen '%v', got '%v'", want, got)
+	}
+}
+
+func TestHandleLineWithObjectInfo(t *testing.T) {
+	re := newRsyslogExporter()
+	line := `2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"test_input","origin":"imuxsock","submitted":100}`
+	if err := re.handleStatLine([]byte(line)); err != nil {
+		t.Fatalf("expected handling line not to fail, got: %v", err)
+	}
+
+	p, err := re.get(`object_info{kind="input",name="test_input",origin="imuxsock"}`)
+	if err != nil {
+		t.Fatalf("expected object info to exist, got: %v", err)
+	}
+	if want, got := int64(1), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// units lists the units annotated in OpenMetrics exposition. OpenMetrics
+// requires the name of a family to end with its unit, so only families named
+// accordingly carry a unit.
+var units = []string{"bytes", "seconds"}
+
+// unitGatherer sets the unit of the metric families it gathers.
+type unitGatherer struct {
+	prometheus.Gatherer
+}
+
+func (g unitGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	for _, mf := range mfs {
+		if unit := familyUnit(mf); unit != "" {
+			mf.Unit = &unit
+		}
+	}
+	return mfs, err
+}
+
+func familyUnit(mf *dto.MetricFamily) string {
+	name := mf.GetName()
+	if mf.GetType() == dto.MetricType_COUNTER {
+		name = strings.TrimSuffix(name, "_total")
+	}
+	for _, unit := range units {
+		if strings.HasSuffix(name, "_"+unit) {
+			return unit
+		}
+	}
+	return ""
+}
+
+// acceptsGzip returns whether the Accept-Encoding header value accepts gzip,
+// either by name or by wildcard, with a non-zero quality.
+func acceptsGzip(header string) bool {
+	wildcard := false
+	for _, coding := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if k, v, ok := strings.Cut(param, "="); ok && strings.TrimSpace(k) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip":
+			return q > 0
+		case "*":
+			wildcard = q > 0
+		}
+	}
+	return wildcard
+}
+
+// validateOpenMetrics returns an error if OpenMetrics is enabled with a
+// naming scheme exporting counters without the _total suffix, which
+// OpenMetrics requires.
+func validateOpenMetrics(enabled bool, scheme string) error {
+	if enabled && scheme != namingConformant {
+		return fmt.Errorf("OpenMetrics requires the %q naming scheme, got %q", namingConformant, scheme)
+	}
+	return nil
+}
+
+// newMetricsHandler returns a handler serving the metrics of g. With
+// openMetrics enabled, scrapes negotiating OpenMetrics get units and _created
+// samples, which promhttp does not encode. All other scrapes are served by
+// promhttp.
+func newMetricsHandler(g prometheus.Gatherer, openMetrics bool) http.Handler {
+	fallback := promhttp.HandlerFor(g, promhttp.HandlerOpts{})
+	if !openMetrics {
+		return fallback
+	}
+	g = unitGatherer{g}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := expfmt.NegotiateIncludingOpenMetrics(r.Header)
+		if format.FormatType() != expfmt.TypeOpenMetrics {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		mfs, err := g.Gather()
+		if err != nil {
+			log.Printf("error gathering metrics: %v", err)
+			http.Error(w, "An error has occurred while gathering metrics:\n\n"+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", string(format))
+		w.Header().Add("Vary", "Accept-Encoding")
+		var out io.Writer = w
+		if acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		}
+
+		enc := expfmt.NewEncoder(out, format, expfmt.WithUnit(), expfmt.WithCreatedLines())
+		for _, mf := range mfs {
+			if err := enc.Encode(mf); err != nil {
+				log.Printf("error encoding metric family %s: %v", mf.GetName(), err)
+				return
+			}
+		}
+		if closer, ok := enc.(expfmt.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Printf("error finishing metrics: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+const openMetricsAccept = "application/openmetrics-text;version=1.0.0"
+
+func scrape(t *testing.T, url, accept string) (string, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Header.Get("Content-Type"), string(b)
+}
+
+// checkOpenMetrics parses an OpenMetrics exposition, failing on unknown or
+// duplicate families, and on samples not belonging to the family declared
+// before them.
+func checkOpenMetrics(t *testing.T, body string) map[string]string {
+	t.Helper()
+	suffixes := map[string][]string{
+		"counter": {"_total", "_created"},
+		"gauge":   {""},
+		"summary": {"", "_sum", "_count", "_created"},
+		"info":    {"_info"},
+	}
+
+	types := map[string]string{}
+	var family string
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if lines[len(lines)-1] != "# EOF" {
+		t.Errorf("expected output to end with # EOF")
+	}
+	for _, line := range lines[:len(lines)-1] {
+		switch {
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			if len(fields) != 4 {
+				t.Errorf("invalid TYPE line %q", line)
+				continue
+			}
+			family = fields[2]
+			if _, ok := types[family]; ok {
+				t.Errorf("family %s declared twice", family)
+			}
+			if _, ok := suffixes[fields[3]]; !ok {
+				t.Errorf("family %s has type %s", family, fields[3])
+			}
+			types[family] = fields[3]
+		case strings.HasPrefix(line, "# "):
+		default:
+			name := line[:strings.IndexAny(line, "{ ")]
+			valid := false
+			for _, suffix := range suffixes[types[family]] {
+				valid = valid || name == family+suffix
+			}
+			if !valid {
+				t.Errorf("sample %s does not belong to family %s of type %s", name, family, types[family])
+			}
+		}
+	}
+	return types
+}
+
+func TestMetricsHandlerOpenMetrics(t *testing.T) {
+	re := newRsyslogExporter()
+	re.namingScheme = namingConformant
+	re.now = func() time.Time { return time.Unix(1000, 0) }
+	re.set(&impstats.Point{Name: "forward_bytes_total", Type: impstats.Counter, Value: 150, Description: "bytes forwarded", LabelName: "destination", LabelValue: "loghost"})
+	re.set(&impstats.Point{Name: "forward_bytes_total", Type: impstats.Counter, Value: 100, Description: "bytes forwarded", LabelName: "destination", LabelValue: "loghost"})
+	re.set(&impstats.Point{Name: "queue_size", Type: impstats.Gauge, Value: 10, Description: "messages currently in queue", LabelName: "queue", LabelValue: "main Q"})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(re)
+	srv := httptest.NewServer(newMetricsHandler(reg, true))
+	defer srv.Close()
+
+	ct, body := scrape(t, srv.URL, openMetricsAccept)
+	if !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Errorf("expected OpenMetrics content type, got %s", ct)
+	}
+	checkOpenMetrics(t, body)
+	for _, want := range []string{
+		"# UNIT rsyslog_forward_bytes bytes\n",
+		`rsyslog_forward_bytes_total{destination="loghost"} 100.0`,
+		`rsyslog_forward_bytes_created{destination="loghost"} 1000`,
+		"# EOF\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{"# UNIT rsyslog_queue_size", "rsyslog_queue_size_created"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("expected output not to contain %q, got:\n%s", unwanted, body)
+		}
+	}
+
+	if ct, _ := scrape(t, srv.URL, ""); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected text format without negotiation, got %s", ct)
+	}
+}
+
+func TestMetricsHandlerOpenMetricsFixture(t *testing.T) {
+	f, err := os.Open("fixtures/rsyslog-stats.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	re := newRsyslogExporter()
+	re.namingScheme = namingConformant
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		re.handleStatLine(scanner.Bytes())
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(re)
+	srv := httptest.NewServer(newMetricsHandler(reg, true))
+	defer srv.Close()
+
+	_, body := scrape(t, srv.URL, openMetricsAccept)
+	types := checkOpenMetrics(t, body)
+	for family, typ := range map[string]string{
+		"rsyslog_action_processed": "counter",
+		"rsyslog_queue_size":       "gauge",
+	} {
+		if want, got := typ, types[family]; want != got {
+			t.Errorf("%s: want type '%s', got '%s'", family, want, got)
+		}
+	}
+}
+
+func TestMetricsHandlerOpenMetricsDisabled(t *testing.T) {
+	re := newRsyslogExporter()
+	re.set(&impstats.Point{Name: "action_processed", Type: impstats.Counter, Value: 100, LabelName: "action", LabelValue: "a"})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(re)
+	srv := httptest.NewServer(newMetricsHandler(reg, false))
+	defer srv.Close()
+
+	ct, body := scrape(t, srv.URL, openMetricsAccept)
+	if !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected text format with OpenMetrics disabled, got %s", ct)
+	}
+	if strings.Contains(body, "_created") {
+		t.Errorf("expected no _created samples, got:\n%s", body)
+	}
+}
+
+func TestValidateOpenMetrics(t *testing.T) {
+	for _, scheme := range []string{namingLegacy, namingConformant, namingBoth} {
+		if err := validateOpenMetrics(false, scheme); err != nil {
+			t.Errorf("%s: expected disabled OpenMetrics to be valid, got: %v", scheme, err)
+		}
+	}
+	if err := validateOpenMetrics(true, namingConformant); err != nil {
+		t.Errorf("expected OpenMetrics with conformant names to be valid, got: %v", err)
+	}
+	for _, scheme := range []string{namingLegacy, namingBoth} {
+		if err := validateOpenMetrics(true, scheme); err == nil {
+			t.Errorf("%s: expected OpenMetrics to be refused", scheme)
+		}
+	}
+}
+
+func TestMetricsHandlerOpenMetricsGzip(t *testing.T) {
+	re := newRsyslogExporter()
+	re.namingScheme = namingConformant
+	re.set(&impstats.Point{Name: "action_processed", Type: impstats.Counter, Value: 100, LabelName: "action", LabelValue: "a"})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(re)
+	srv := httptest.NewServer(newMetricsHandler(reg, true))
+	defer srv.Close()
+
+	testCases := map[string]bool{
+		"":                     false,
+		"gzip":                 true,
+		"deflate, gzip;q=0.5":  true,
+		"gzip;q=0":             false,
+		"gzip; q=0.0, deflate": false,
+		"*":                    true,
+		"*;q=0":                false,
+		"gzip;q=0, *":          false,
+	}
+
+	for acceptEncoding, gzipped := range testCases {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", openMetricsAccept)
+		// Setting Accept-Encoding keeps the client from negotiating and
+		// decompressing gzip itself.
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := resp.Header.Get("Content-Encoding") == "gzip"; gzipped != got {
+			t.Errorf("%q: want gzip '%v', got '%v'", acceptEncoding, gzipped, got)
+		}
+		if want, got := "Accept-Encoding", resp.Header.Get("Vary"); want != got {
+			t.Errorf("%q: want Vary '%s', got '%s'", acceptEncoding, want, got)
+		}
+		if gzipped {
+			zr, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("%q: expected gzipped body, got: %v", acceptEncoding, err)
+			}
+			if b, err = io.ReadAll(zr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !strings.HasSuffix(string(b), "# EOF\n") {
+			t.Errorf("%q: expected OpenMetrics body, got:\n%s", acceptEncoding, b)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+const (
+	overflowBlock      = "block"
+	overflowDropOldest = "drop-oldest"
+)
+
+// dynStatsOrigin is the origin of the global dynstats object, and the prefix
+// of the origin of dynstats buckets.
+const dynStatsOrigin = "dynstats"
+
+func validateOverflowPolicy(policy string) error {
+	switch policy {
+	case overflowBlock, overflowDropOldest:
+		return nil
+	}
+	return fmt.Errorf("invalid overflow policy %q, must be one of %q or %q", policy, overflowBlock, overflowDropOldest)
+}
+
+// pipeline hands stats lines from the reader to parser workers through
+// bounded queues, so that reading from rsyslog does not stall while lines
+// are handled. Every worker has its own queue, and all lines of an object go
+// to the same worker, which keeps them in order. Once a queue is full, the
+// reader either blocks or drops the oldest line of the queue, depending on
+// the overflow policy.
+type pipeline struct {
+	queues  []chan []byte
+	policy  string
+	dropped atomic.Int64
+	wg      sync.WaitGroup
+	// storeLock serializes store, so that concurrent collections do not
+	// store the dropped lines out of order.
+	storeLock sync.Mutex
+}
+
+func newPipeline(workers, queueSize int, policy string) (*pipeline, error) {
+	if workers < 1 {
+		return nil, fmt.Errorf("number of workers must be at least 1, got %d", workers)
+	}
+	if queueSize < 1 {
+		return nil, fmt.Errorf("queue size must be at least 1, got %d", queueSize)
+	}
+	if err := validateOverflowPolicy(policy); err != nil {
+		return nil, err
+	}
+	p := &pipeline{
+		queues: make([]chan []byte, workers),
+		policy: policy,
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan []byte, queueSize)
+	}
+	return p, nil
+}
+
+// start starts the workers, each calling handle for the lines of its queue.
+func (p *pipeline) start(handle func(line []byte)) {
+	for _, q := range p.queues {
+		p.wg.Add(1)
+		go func(q chan []byte) {
+			defer p.wg.Done()
+			for line := range q {
+				handle(line)
+			}
+		}(q)
+	}
+}
+
+// enqueue queues a line for its worker. The pipeline takes ownership of the
+// line, so it must not be modified afterwards. enqueue must only be called
+// by a single reader.
+func (p *pipeline) enqueue(line []byte) {
+	q := p.queues[p.worker(line)]
+	if p.policy == overflowBlock {
+		q <- line
+		return
+	}
+	for {
+		select {
+		case q <- line:
+			return
+		default:
+		}
+		select {
+		case <-q:
+			p.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// worker returns the index of the worker handling a line. Lines are assigned
+// by the origin and name of their object, except for dynstats, whose global
+// object (origin dynstats) and buckets (origin dynstats.bucket) all go to the
+// same worker, as the purges reported by the global object have to be seen
+// before the next report of the purged bucket.
+func (p *pipeline) worker(line []byte) int {
+	if len(p.queues) == 1 {
+		return 0
+	}
+	_, buf, err := impstats.SplitLine(line)
+	if err != nil {
+		return 0
+	}
+	h := fnv.New32a()
+	if origin := impstats.Origin(buf); strings.HasPrefix(origin, dynStatsOrigin) {
+		h.Write([]byte(dynStatsOrigin))
+	} else {
+		h.Write([]byte(origin))
+		h.Write([]byte{0})
+		h.Write([]byte(impstats.ObjectName(buf)))
+	}
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// close stops accepting lines and waits for the workers to handle all
+// queued lines.
+func (p *pipeline) close() {
+	for _, q := range p.queues {
+		close(q)
+	}
+	p.wg.Wait()
+}
+
+// store stores the points describing the state of the queues with set.
+func (p *pipeline) store(set func(*impstats.Point) error) {
+	p.storeLock.Lock()
+	defer p.storeLock.Unlock()
+	for _, pt := range p.points() {
+		set(pt)
+	}
+}
+
+// points returns the points describing the state of the queues.
+func (p *pipeline) points() []*impstats.Point {
+	var depth, capacity int
+	for _, q := range p.queues {
+		depth += len(q)
+		capacity += cap(q)
+	}
+
+	points := make([]*impstats.Point, 3)
+	points[0] = &impstats.Point{
+		Name:        "exporter_queue_depth",
+		Type:        impstats.Gauge,
+		Value:       int64(depth),
+		Description: "stats lines waiting in the queues of the parser workers",
+	}
+	points[1] = &impstats.Point{
+		Name:        "exporter_queue_capacity",
+		Type:        impstats.Gauge,
+		Value:       int64(capacity),
+		Description: "maximum number of stats lines in the queues of the parser workers",
+	}
+	points[2] = &impstats.Point{
+		Name:        "exporter_lines_dropped_total",
+		Type:        impstats.Counter,
+		Value:       p.dropped.Load(),
+		Description: "stats lines dropped because the queue of their parser worker was full",
+	}
+	return points
+}
@@ -0,0 +1,239 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+func actionLine(name string, processed int) []byte {
+	return []byte(fmt.Sprintf(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"%s","origin":"core.action","processed":%d,"failed":0,"suspended":0,"suspended.duration":0,"resumed":0}`, name, processed))
+}
+
+func TestNewPipeline(t *testing.T) {
+	tests := []struct {
+		workers, queueSize int
+		policy             string
+		valid              bool
+	}{
+		{1, 1, overflowBlock, true},
+		{4, 100, overflowDropOldest, true},
+		{0, 100, overflowBlock, false},
+		{1, 0, overflowBlock, false},
+		{1, 100, "drop-newest", false},
+	}
+	for _, tc := range tests {
+		_, err := newPipeline(tc.workers, tc.queueSize, tc.policy)
+		if tc.valid && err != nil {
+			t.Errorf("%+v: expected no error, got: %v", tc, err)
+		} else if !tc.valid && err == nil {
+			t.Errorf("%+v: expected error", tc)
+		}
+	}
+}
+
+func TestPipelineWorkerAssignment(t *testing.T) {
+	p, err := newPipeline(8, 1, overflowBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := p.worker(actionLine("a", 1)), p.worker(actionLine("a", 2)); want != got {
+		t.Errorf("expected lines of the same object on the same worker, got %d and %d", want, got)
+	}
+
+	escaped := []byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"imptcp(*\/var\/run\/log.sock)","origin":"imptcp","submitted":1}`)
+	unescaped := []byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"origin":"imptcp","name":"imptcp(*/var/run/log.sock)","submitted":2}`)
+	if want, got := p.worker(escaped), p.worker(unescaped); want != got {
+		t.Errorf("expected lines of the same object on the same worker regardless of escaping, got %d and %d", want, got)
+	}
+
+	global := []byte(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"global","origin":"dynstats","values":{"msg_per_host.purge_triggered":1}}`)
+	for _, name := range []string{"msg_per_host", "msg_per_app", "msg_per_severity", "bytes_per_host", "errors", "a", "b", "c"} {
+		bucket := []byte(fmt.Sprintf(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"%s","origin":"dynstats.bucket","values":{"host1":1}}`, name))
+		if want, got := p.worker(global), p.worker(bucket); want != got {
+			t.Errorf("%s: expected dynstats lines on the worker of the global object %d, got %d", name, want, got)
+		}
+	}
+
+	if want, got := 0, p.worker([]byte("broken")); want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
+
+func TestPipelineKeepsObjectOrder(t *testing.T) {
+	p, err := newPipeline(4, 10, overflowBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	handled := map[string][]int{}
+	p.start(func(line []byte) {
+		a := parseTestAction(t, line)
+		if a == nil {
+			return
+		}
+		lock.Lock()
+		handled[a.Name] = append(handled[a.Name], int(a.Processed))
+		lock.Unlock()
+	})
+
+	names := []string{"a", "b", "c", "d", "e", "f"}
+	for i := 0; i < 100; i++ {
+		for _, name := range names {
+			p.enqueue(actionLine(name, i))
+		}
+	}
+	p.close()
+
+	for _, name := range names {
+		values := handled[name]
+		if want, got := 100, len(values); want != got {
+			t.Fatalf("%s: want '%d' lines, got '%d'", name, want, got)
+		}
+		for i, v := range values {
+			if i != v {
+				t.Fatalf("%s: lines out of order: %v", name, values)
+			}
+		}
+	}
+}
+
+func TestPipelineDropOldest(t *testing.T) {
+	p, err := newPipeline(1, 2, overflowDropOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		p.enqueue(actionLine("a", i))
+	}
+
+	points := p.points()
+	if want, got := int64(2), points[0].Value; want != got {
+		t.Errorf("depth: want '%d', got '%d'", want, got)
+	}
+	if want, got := int64(2), points[1].Value; want != got {
+		t.Errorf("capacity: want '%d', got '%d'", want, got)
+	}
+	if want, got := int64(3), points[2].Value; want != got {
+		t.Errorf("dropped: want '%d', got '%d'", want, got)
+	}
+
+	var handled []int
+	p.start(func(line []byte) {
+		if a := parseTestAction(t, line); a != nil {
+			handled = append(handled, int(a.Processed))
+		}
+	})
+	p.close()
+	if want, got := "[3 4]", fmt.Sprint(handled); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
+
+func TestPipelineBlock(t *testing.T) {
+	p, err := newPipeline(1, 1, overflowBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.enqueue(actionLine("a", 0))
+	done := make(chan struct{})
+	go func() {
+		p.enqueue(actionLine("a", 1))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected enqueue to block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	p.start(func([]byte) {})
+	<-done
+	p.close()
+	if want, got := int64(0), p.points()[2].Value; want != got {
+		t.Errorf("dropped: want '%d', got '%d'", want, got)
+	}
+}
+
+// TestPipelineConcurrentCollect is meant to be run with -race.
+func TestPipelineConcurrentCollect(t *testing.T) {
+	re := newRsyslogExporter()
+	p, err := newPipeline(4, 10, overflowBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re.pipeline = p
+	re.pipeline.start(func(line []byte) {
+		re.handleLine(line, true)
+	})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(re)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if _, err := reg.Gather(); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		re.pipeline.enqueue(actionLine(fmt.Sprintf("a%d", i%8), i))
+		re.pipeline.enqueue([]byte(fmt.Sprintf(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"resource-usage","origin":"impstats","utime":%d,"stime":1,"maxrss":1,"minflt":1,"majflt":0,"inblock":0,"oublock":0,"nvcsw":1,"nivcsw":1,"openfiles":1}`, 1000-i)))
+		re.pipeline.enqueue([]byte("broken"))
+	}
+	re.pipeline.close()
+	wg.Wait()
+
+	pt, err := re.get(`action_processed{action="a7",action_index="",builtin="",module=""}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(199), pt.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+	pt, err = re.get(`stats_line_errors{origin="",reason="split"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(200), pt.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
+
+// parseTestAction decodes an action line as created by actionLine.
+func parseTestAction(t *testing.T, line []byte) *impstats.Action {
+	_, obj, err := impstats.ParseLine(line)
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	return obj.(*impstats.Action)
+}
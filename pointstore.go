@@ -15,23 +15,69 @@ package main
 
 import (
 	"errors"
+	"hash/fnv"
 	"sort"
 	"sync"
+	"time"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
 )
 
 var (
-	errPointNotFound = errors.New("point does not exist")
+	errPointNotFound     = errors.New("point does not exist")
+	errSeriesLimitExceed = errors.New("series limit of metric family exceeded")
+	errOutOfOrder        = errors.New("point is older than the stored point")
 )
 
+const seriesDroppedName = "exporter_series_dropped_total"
+
+// maxDroppedSeries is the number of dropped series remembered per family, so
+// that each of them is counted once. Further dropped series are not counted.
+const maxDroppedSeries = 1 << 16
+
 type pointStore struct {
-	pointMap map[string]*point
+	pointMap map[string]*impstats.Point
 	lock     *sync.RWMutex
+
+	limits *seriesLimits
+	// series counts the series stored per family, including aggregated
+	// series but not overflow series.
+	series map[string]int
+	// folded maps the keys of series folded into an overflow series to the
+	// key of the overflow series. foldedSeries counts them per family, at
+	// most as many as the limit of the family, further series are dropped.
+	folded       map[string]string
+	foldedSeries map[string]int
+	// aggregated maps the keys of series summed up by aggregation rules to
+	// the key of the aggregated series, and aggregates holds the keys of the
+	// aggregated series.
+	aggregated map[string]string
+	aggregates map[string]bool
+	// contributions holds the last values of the series summed up into
+	// overflow and aggregated series, by the key of the sum and the key of
+	// the original series.
+	contributions map[string]map[string]int64
+	// dropped counts the dropped series per family, and droppedSeries holds
+	// the hashes of their keys.
+	dropped       map[string]int64
+	droppedSeries map[string]map[uint64]bool
+
+	now func() time.Time
 }
 
 func newPointStore() *pointStore {
 	return &pointStore{
-		pointMap: make(map[string]*point),
-		lock:     &sync.RWMutex{},
+		pointMap:      make(map[string]*impstats.Point),
+		lock:          &sync.RWMutex{},
+		series:        make(map[string]int),
+		folded:        make(map[string]string),
+		foldedSeries:  make(map[string]int),
+		aggregated:    make(map[string]string),
+		aggregates:    make(map[string]bool),
+		contributions: make(map[string]map[string]int64),
+		dropped:       make(map[string]int64),
+		droppedSeries: make(map[string]map[uint64]bool),
+		now:           time.Now,
 	}
 }
 
@@ -46,20 +92,223 @@ func (ps *pointStore) keys() []string {
 	return keys
 }
 
-func (ps *pointStore) set(p *point) error {
+func (ps *pointStore) set(p *impstats.Point) error {
 	var err error
 	ps.lock.Lock()
-	ps.pointMap[p.key()] = p
+	key := p.Key()
+	if last, ok := ps.pointMap[key]; ok {
+		if !p.Timestamp.IsZero() && p.Timestamp.Before(last.Timestamp) {
+			ps.lock.Unlock()
+			return errOutOfOrder
+		}
+		ps.track(key, p)
+		ps.pointMap[key] = p
+	} else if overflowKey, ok := ps.folded[key]; ok {
+		ps.fold(overflowKey, key, overflowPoint(p))
+	} else if limit := ps.limits.forFamily(p.Name); limit > 0 && ps.series[p.Name] >= limit {
+		err = ps.overflow(key, p, limit)
+	} else {
+		ps.track(key, p)
+		ps.pointMap[key] = p
+		ps.series[p.Name]++
+		delete(ps.droppedSeries[p.Name], seriesHash(key))
+	}
 	ps.lock.Unlock()
 	return err
 }
 
-func (ps *pointStore) get(name string) (*point, error) {
+// overflow handles a new series of a family that reached its limit. It has
+// to be called with the lock held.
+func (ps *pointStore) overflow(key string, p *impstats.Point, limit int) error {
+	if ps.limits.Overflow == overflowFold && p.Type != impstats.Summary && ps.foldedSeries[p.Name] < limit {
+		o := overflowPoint(p)
+		ps.folded[key] = o.Key()
+		ps.foldedSeries[p.Name]++
+		ps.fold(o.Key(), key, o)
+		return nil
+	}
+	ps.drop(key, p)
+	return errSeriesLimitExceed
+}
+
+// drop counts a dropped series of a family that reached its limit. It has to
+// be called with the lock held.
+func (ps *pointStore) drop(key string, p *impstats.Point) {
+	seen := ps.droppedSeries[p.Name]
+	if seen == nil {
+		seen = make(map[uint64]bool)
+		ps.droppedSeries[p.Name] = seen
+	}
+	if h := seriesHash(key); !seen[h] && len(seen) < maxDroppedSeries {
+		seen[h] = true
+		ps.dropped[p.Name]++
+		d := &impstats.Point{
+			Name:        seriesDroppedName,
+			Type:        impstats.Counter,
+			Value:       ps.dropped[p.Name],
+			Description: "series dropped due to the series limit of their metric family",
+			LabelName:   "family",
+			LabelValue:  p.Name,
+		}
+		ps.pointMap[d.Key()] = d
+	}
+}
+
+// aggregate records the value of the series with the given key, summed up
+// into the aggregated point a. A new aggregated series counts against the
+// limit of its family like any other series, and is dropped once the family
+// reached its limit.
+func (ps *pointStore) aggregate(key string, a *impstats.Point) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	sumKey := a.Key()
+	if last, ok := ps.aggregated[key]; ok && last != sumKey {
+		delete(ps.aggregated, key)
+		delete(ps.contributions[last], key)
+		ps.updateSum(last, nil)
+	}
+	if !ps.aggregates[sumKey] {
+		if limit := ps.limits.forFamily(a.Name); limit > 0 && ps.series[a.Name] >= limit {
+			ps.drop(sumKey, a)
+			return errSeriesLimitExceed
+		}
+		ps.aggregates[sumKey] = true
+		ps.series[a.Name]++
+	}
+	ps.aggregated[key] = sumKey
+	ps.fold(sumKey, key, a)
+	return nil
+}
+
+// fold records the contribution of the series with the given key to a sum
+// and updates the sum. The contribution carries the labels of the sum. It has
+// to be called with the lock held.
+func (ps *pointStore) fold(sumKey, key string, p *impstats.Point) {
+	if ps.contributions[sumKey] == nil {
+		ps.contributions[sumKey] = make(map[string]int64)
+	}
+	ps.contributions[sumKey][key] = p.Value
+	ps.updateSum(sumKey, p)
+}
+
+// updateSum recalculates an overflow or aggregated series from the values of
+// its contributions, taking all other fields from p, the latest contribution,
+// or from the stored sum if p is nil. The timestamp of the sum is the latest
+// timestamp of its contributions. It has to be called with the lock held.
+func (ps *pointStore) updateSum(sumKey string, p *impstats.Point) {
+	last := ps.pointMap[sumKey]
+	if len(ps.contributions[sumKey]) == 0 || (p == nil && last == nil) {
+		delete(ps.contributions, sumKey)
+		delete(ps.pointMap, sumKey)
+		if ps.aggregates[sumKey] {
+			delete(ps.aggregates, sumKey)
+			ps.series[last.Name]--
+		}
+		return
+	}
+	if p == nil {
+		p = last
+	}
+	sum := *p
+	if last != nil && last.Timestamp.After(sum.Timestamp) {
+		sum.Timestamp = last.Timestamp
+	}
+	sum.Value = 0
+	for _, v := range ps.contributions[sumKey] {
+		sum.Value += v
+	}
+	ps.track(sumKey, &sum)
+	ps.pointMap[sumKey] = &sum
+}
+
+// track sets the created timestamp of a counter or summary point about to be
+// stored under key. It is kept from the stored point, unless the value went
+// backwards, which means the series was reset at the current time. The start
+// of a new series is unknown, so it has no created timestamp until it is
+// reset. It has to be called with the lock held.
+func (ps *pointStore) track(key string, p *impstats.Point) {
+	if p.Type == impstats.Gauge {
+		return
+	}
+	last, ok := ps.pointMap[key]
+	if !ok {
+		return
+	}
+	if last.ResetBy(p) {
+		p.Created = ps.now()
+		return
+	}
+	p.Created = last.Created
+}
+
+// restarted marks all counters and summaries as reset at the current time.
+func (ps *pointStore) restarted() {
+	ps.lock.Lock()
+	now := ps.now()
+	for key, p := range ps.pointMap {
+		if p.Type != impstats.Gauge {
+			r := *p
+			r.Created = now
+			ps.pointMap[key] = &r
+		}
+	}
+	ps.lock.Unlock()
+}
+
+func (ps *pointStore) delete(name string) {
+	ps.lock.Lock()
+	if p, ok := ps.pointMap[name]; ok {
+		delete(ps.pointMap, name)
+		if ps.aggregates[name] {
+			delete(ps.aggregates, name)
+			delete(ps.contributions, name)
+			ps.series[p.Name]--
+		} else if _, ok := ps.contributions[name]; !ok && p.Name != seriesDroppedName {
+			ps.series[p.Name]--
+		}
+	} else if overflowKey, ok := ps.folded[name]; ok {
+		delete(ps.folded, name)
+		if o, ok := ps.pointMap[overflowKey]; ok {
+			ps.foldedSeries[o.Name]--
+		}
+		delete(ps.contributions[overflowKey], name)
+		ps.updateSum(overflowKey, nil)
+	}
+	if sumKey, ok := ps.aggregated[name]; ok {
+		delete(ps.aggregated, name)
+		delete(ps.contributions[sumKey], name)
+		ps.updateSum(sumKey, nil)
+	}
+	ps.lock.Unlock()
+}
+
+func (ps *pointStore) get(name string) (*impstats.Point, error) {
 	ps.lock.Lock()
 	if p, ok := ps.pointMap[name]; ok {
 		ps.lock.Unlock()
 		return p, nil
 	}
 	ps.lock.Unlock()
-	return &point{}, errPointNotFound
+	return &impstats.Point{}, errPointNotFound
+}
+
+// seriesHash returns the hash by which dropped series are remembered.
+func seriesHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// overflowPoint returns a copy of p with all label values replaced by
+// overflowLabelValue.
+func overflowPoint(p *impstats.Point) *impstats.Point {
+	o := *p
+	if o.LabelName != "" {
+		o.LabelValue = overflowLabelValue
+	}
+	o.ExtraLabels = make([]impstats.Label, len(p.ExtraLabels))
+	for i, l := range p.ExtraLabels {
+		o.ExtraLabels[i] = impstats.Label{Name: l.Name, Value: overflowLabelValue}
+	}
+	return &o
 }
@@ -13,20 +13,25 @@
 
 package main
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
 
 func TestPointStore(t *testing.T) {
 	ps := newPointStore()
 
-	s1 := &point{
+	s1 := &impstats.Point{
 		Name:  "my counter",
-		Type:  counter,
+		Type:  impstats.Counter,
 		Value: int64(10),
 	}
 
-	s2 := &point{
+	s2 := &impstats.Point{
 		Name:  "my counter",
-		Type:  counter,
+		Type:  impstats.Counter,
 		Value: int64(5),
 	}
 
@@ -35,7 +40,7 @@ func TestPointStore(t *testing.T) {
 		t.Error(err)
 	}
 
-	got, err := ps.get(s1.key())
+	got, err := ps.get(s1.Key())
 	if err != nil {
 		t.Error(err)
 	}
@@ -49,7 +54,7 @@ func TestPointStore(t *testing.T) {
 		t.Error(err)
 	}
 
-	got, err = ps.get(s2.key())
+	got, err = ps.get(s2.Key())
 	if err != nil {
 		t.Error(err)
 	}
@@ -58,9 +63,9 @@ func TestPointStore(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	s3 := &point{
+	s3 := &impstats.Point{
 		Name:  "my gauge",
-		Type:  gauge,
+		Type:  impstats.Gauge,
 		Value: int64(20),
 	}
 
@@ -69,7 +74,7 @@ func TestPointStore(t *testing.T) {
 		t.Error(err)
 	}
 
-	got, err = ps.get(s3.key())
+	got, err = ps.get(s3.Key())
 	if err != nil {
 		t.Error(err)
 	}
@@ -78,9 +83,9 @@ func TestPointStore(t *testing.T) {
 		t.Errorf("want '%d', got '%d'", want, got)
 	}
 
-	s4 := &point{
+	s4 := &impstats.Point{
 		Name:  "my gauge",
-		Type:  gauge,
+		Type:  impstats.Gauge,
 		Value: int64(15),
 	}
 
@@ -89,7 +94,7 @@ func TestPointStore(t *testing.T) {
 		t.Error(err)
 	}
 
-	got, err = ps.get(s4.key())
+	got, err = ps.get(s4.Key())
 	if err != nil {
 		t.Error(err)
 	}
@@ -103,3 +108,178 @@ func TestPointStore(t *testing.T) {
 		t.Error("getting non existent point should raise error")
 	}
 }
+
+func TestPointStoreLimitDrop(t *testing.T) {
+	ps := newPointStore()
+	ps.limits = &seriesLimits{
+		Default:         1,
+		DynstatsBuckets: map[string]int{"msg_per_host": 2},
+		Overflow:        overflowDrop,
+	}
+
+	for _, name := range []string{"host1", "host2", "host3"} {
+		ps.set(&impstats.Point{Name: "dynstat_msg_per_host", Value: 1, LabelName: "counter", LabelValue: name})
+	}
+	for _, name := range []string{"a", "b"} {
+		ps.set(&impstats.Point{Name: "action_processed", Value: 1, LabelName: "action", LabelValue: name})
+	}
+	ps.set(&impstats.Point{Name: "dynstat_msg_per_host", Value: 2, LabelName: "counter", LabelValue: "host3"})
+
+	for _, key := range []string{`dynstat_msg_per_host{counter="host1"}`, `dynstat_msg_per_host{counter="host2"}`, `action_processed{action="a"}`} {
+		if _, err := ps.get(key); err != nil {
+			t.Errorf("%s: %v", key, err)
+		}
+	}
+	for _, key := range []string{`dynstat_msg_per_host{counter="host3"}`, `action_processed{action="b"}`} {
+		if _, err := ps.get(key); err != errPointNotFound {
+			t.Errorf("%s: want series to be dropped", key)
+		}
+	}
+
+	// host3 is counted once, although both of its updates were dropped.
+	got, err := ps.get(`exporter_series_dropped_total{family="dynstat_msg_per_host"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(1), got.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+
+	// Deleting a series makes room for a new one.
+	ps.delete(`dynstat_msg_per_host{counter="host1"}`)
+	if err := ps.set(&impstats.Point{Name: "dynstat_msg_per_host", Value: 3, LabelName: "counter", LabelValue: "host3"}); err != nil {
+		t.Errorf("want series to be stored after delete, got: %v", err)
+	}
+}
+
+func TestPointStoreLimitFold(t *testing.T) {
+	ps := newPointStore()
+	ps.limits = &seriesLimits{
+		Families: map[string]int{"dynstat_msg_per_host": 2},
+		Overflow: overflowFold,
+	}
+
+	for i, name := range []string{"host1", "host2", "host3", "host4", "host5"} {
+		ps.set(&impstats.Point{Name: "dynstat_msg_per_host", Value: int64(i + 1), LabelName: "counter", LabelValue: name})
+	}
+	ps.set(&impstats.Point{Name: "dynstat_msg_per_host", Value: 5, LabelName: "counter", LabelValue: "host3"})
+	ps.set(&impstats.Point{Name: "dynstat_msg_per_host", Value: 6, LabelName: "counter", LabelValue: "host5"})
+
+	got, err := ps.get(`dynstat_msg_per_host{counter="__overflow__"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(9), got.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+
+	ps.delete(`dynstat_msg_per_host{counter="host3"}`)
+	got, err = ps.get(`dynstat_msg_per_host{counter="__overflow__"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(4), got.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+
+	// No more series than the limit are folded, further series are dropped.
+	got, err = ps.get(`exporter_series_dropped_total{family="dynstat_msg_per_host"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(1), got.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+	if want, got := 1, ps.foldedSeries["dynstat_msg_per_host"]; want != got {
+		t.Errorf("want '%d' folded series, got '%d'", want, got)
+	}
+}
+
+func TestPointStoreLimitAggregate(t *testing.T) {
+	ps := newPointStore()
+	ps.limits = &seriesLimits{
+		Families: map[string]int{"input_received": 2},
+		Overflow: overflowFold,
+	}
+
+	ps.set(&impstats.Point{Name: "input_received", Type: impstats.Counter, Value: 1, LabelName: "input", LabelValue: "imtcp"})
+	for i, name := range []string{"imudp(w0)", "imudp(w1)"} {
+		a := &impstats.Point{Name: "input_received", Type: impstats.Counter, Value: int64(i + 1), LabelName: "input", LabelValue: "imudp"}
+		if err := ps.aggregate(`input_received{input="`+name+`"}`, a); err != nil {
+			t.Fatalf("expected aggregating series not to fail, got: %v", err)
+		}
+	}
+
+	// The aggregated series takes up the second series of the family.
+	if err := ps.set(&impstats.Point{Name: "input_received", Type: impstats.Counter, Value: 4, LabelName: "input", LabelValue: "imptcp"}); err != nil {
+		t.Fatalf("expected new series to be folded, got: %v", err)
+	}
+	if _, err := ps.get(`input_received{input="__overflow__"}`); err != nil {
+		t.Errorf("expected overflow series to exist, got: %v", err)
+	}
+
+	a := &impstats.Point{Name: "input_received", Type: impstats.Counter, Value: 5, LabelName: "input", LabelValue: "imrelp"}
+	if err := ps.aggregate(`input_received{input="imrelp(w0)"}`, a); err != errSeriesLimitExceed {
+		t.Errorf("want '%v', got '%v'", errSeriesLimitExceed, err)
+	}
+	if _, err := ps.get(`input_received{input="imrelp"}`); err != errPointNotFound {
+		t.Errorf("expected aggregated series over the limit to be dropped")
+	}
+
+	// Deleting all contributions of an aggregated series makes room for a
+	// new series.
+	ps.delete(`input_received{input="imudp(w0)"}`)
+	ps.delete(`input_received{input="imudp(w1)"}`)
+	if _, err := ps.get(`input_received{input="imudp"}`); err != errPointNotFound {
+		t.Errorf("expected aggregated series to be deleted")
+	}
+	if err := ps.aggregate(`input_received{input="imrelp(w0)"}`, a); err != nil {
+		t.Errorf("expected aggregating series not to fail, got: %v", err)
+	}
+	if want, got := 2, ps.series["input_received"]; want != got {
+		t.Errorf("want '%d' series, got '%d'", want, got)
+	}
+}
+
+func TestPointStoreCreated(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ps := newPointStore()
+	ps.now = func() time.Time { return now }
+
+	ps.set(&impstats.Point{Name: "action_processed", Type: impstats.Counter, Value: 10, LabelName: "action", LabelValue: "a"})
+	ps.set(&impstats.Point{Name: "queue_size", Type: impstats.Gauge, Value: 10, LabelName: "queue", LabelValue: "q"})
+
+	// The start of a series seen for the first time is unknown.
+	now = time.Unix(2000, 0)
+	ps.set(&impstats.Point{Name: "action_processed", Type: impstats.Counter, Value: 20, LabelName: "action", LabelValue: "a"})
+	p, _ := ps.get(`action_processed{action="a"}`)
+	if !p.Created.IsZero() {
+		t.Errorf("expected series not seen to be reset not to have a created timestamp, got '%v'", p.Created)
+	}
+
+	now = time.Unix(3000, 0)
+	ps.set(&impstats.Point{Name: "action_processed", Type: impstats.Counter, Value: 5, LabelName: "action", LabelValue: "a"})
+	p, _ = ps.get(`action_processed{action="a"}`)
+	if want, got := time.Unix(3000, 0), p.Created; !want.Equal(got) {
+		t.Errorf("expected reset to update created, want '%v', got '%v'", want, got)
+	}
+
+	now = time.Unix(3500, 0)
+	ps.set(&impstats.Point{Name: "action_processed", Type: impstats.Counter, Value: 8, LabelName: "action", LabelValue: "a"})
+	p, _ = ps.get(`action_processed{action="a"}`)
+	if want, got := time.Unix(3000, 0), p.Created; !want.Equal(got) {
+		t.Errorf("expected created to be kept, want '%v', got '%v'", want, got)
+	}
+
+	now = time.Unix(4000, 0)
+	ps.restarted()
+	p, _ = ps.get(`action_processed{action="a"}`)
+	if want, got := time.Unix(4000, 0), p.Created; !want.Equal(got) {
+		t.Errorf("expected restart to update created, want '%v', got '%v'", want, got)
+	}
+
+	p, _ = ps.get(`queue_size{queue="q"}`)
+	if !p.Created.IsZero() {
+		t.Errorf("expected gauge not to have a created timestamp, got '%v'", p.Created)
+	}
+}
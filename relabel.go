@@ -0,0 +1,223 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+const (
+	relabelReplace   = "replace"
+	relabelKeep      = "keep"
+	relabelDrop      = "drop"
+	relabelLabelDrop = "labeldrop"
+	relabelLabelMap  = "labelmap"
+
+	// metricNameLabel holds the metric name, without the rsyslog_ prefix,
+	// during relabeling.
+	metricNameLabel = "__name__"
+)
+
+var (
+	relabelTargetRegexp = regexp.MustCompile(`^(?:(?:[a-zA-Z_]|\$(?:\{\w+\}|\w+))+\w*)+$`)
+)
+
+// relabelConfig is a relabeling step applied to every point before it is
+// stored, with the semantics of Prometheus' metric_relabel_configs.
+type relabelConfig struct {
+	SourceLabels []string `json:"source_labels,omitempty"`
+	Separator    *string  `json:"separator,omitempty"`
+	Regex        *string  `json:"regex,omitempty"`
+	TargetLabel  string   `json:"target_label,omitempty"`
+	Replacement  *string  `json:"replacement,omitempty"`
+	Action       string   `json:"action,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+func (c *relabelConfig) validate() error {
+	if c.Action == "" {
+		c.Action = relabelReplace
+	}
+	if c.Separator == nil {
+		sep := ";"
+		c.Separator = &sep
+	}
+	if c.Replacement == nil {
+		repl := "$1"
+		c.Replacement = &repl
+	}
+	regex := "(.*)"
+	if c.Regex != nil {
+		regex = *c.Regex
+	}
+	re, err := regexp.Compile("^(?:" + regex + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %v", regex, err)
+	}
+	c.regex = re
+
+	switch c.Action {
+	case relabelReplace:
+		if c.TargetLabel == "" {
+			return errors.New("replace action requires target_label")
+		}
+		if !relabelTargetRegexp.MatchString(c.TargetLabel) {
+			return fmt.Errorf("%q is invalid target_label for replace action", c.TargetLabel)
+		}
+	case relabelKeep, relabelDrop:
+		if len(c.SourceLabels) == 0 {
+			return fmt.Errorf("%s action requires source_labels", c.Action)
+		}
+	case relabelLabelDrop:
+		if len(c.SourceLabels) > 0 || c.TargetLabel != "" || *c.Replacement != "$1" || *c.Separator != ";" {
+			return fmt.Errorf("%s action only supports regex", c.Action)
+		}
+	case relabelLabelMap:
+		if !relabelTargetRegexp.MatchString(*c.Replacement) {
+			return fmt.Errorf("%q is invalid replacement for labelmap action", *c.Replacement)
+		}
+	default:
+		return fmt.Errorf("unknown relabel action %q", c.Action)
+	}
+	return nil
+}
+
+// relabel applies the relabel configs to the labels of p. It returns nil if
+// the point is dropped.
+func relabel(p *impstats.Point, cfgs []*relabelConfig) *impstats.Point {
+	if len(cfgs) == 0 {
+		return p
+	}
+
+	labels := append([]impstats.Label{{Name: metricNameLabel, Value: p.Name}}, p.Labels()...)
+	for _, cfg := range cfgs {
+		var keep bool
+		labels, keep = cfg.apply(labels)
+		if !keep {
+			return nil
+		}
+	}
+
+	r := *p
+	r.Name = ""
+	filtered := make([]impstats.Label, 0, len(labels))
+	for _, l := range labels {
+		switch {
+		case l.Name == metricNameLabel:
+			r.Name = l.Value
+		case strings.HasPrefix(l.Name, "__"):
+			// Temporary labels are removed after relabeling.
+		default:
+			filtered = append(filtered, l)
+		}
+	}
+	if r.Name == "" {
+		return nil
+	}
+	r.SetLabels(filtered)
+	return &r
+}
+
+func (c *relabelConfig) apply(labels []impstats.Label) ([]impstats.Label, bool) {
+	values := make([]string, 0, len(c.SourceLabels))
+	for _, name := range c.SourceLabels {
+		values = append(values, labelValue(labels, name))
+	}
+	val := strings.Join(values, *c.Separator)
+
+	switch c.Action {
+	case relabelDrop:
+		if c.regex.MatchString(val) {
+			return nil, false
+		}
+	case relabelKeep:
+		if !c.regex.MatchString(val) {
+			return nil, false
+		}
+	case relabelReplace:
+		indexes := c.regex.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			break
+		}
+		target := string(c.regex.ExpandString([]byte{}, c.TargetLabel, val, indexes))
+		if !labelNameRegexp.MatchString(target) {
+			break
+		}
+		res := string(c.regex.ExpandString([]byte{}, *c.Replacement, val, indexes))
+		if res == "" {
+			labels = deleteLabel(labels, target)
+			break
+		}
+		labels = setLabel(labels, target, res)
+	case relabelLabelDrop:
+		filtered := labels[:0:0]
+		for _, l := range labels {
+			if l.Name == metricNameLabel || !c.regex.MatchString(l.Name) {
+				filtered = append(filtered, l)
+			}
+		}
+		labels = filtered
+	case relabelLabelMap:
+		mapped := labels
+		for _, l := range labels {
+			if c.regex.MatchString(l.Name) {
+				name := c.regex.ReplaceAllString(l.Name, *c.Replacement)
+				mapped = setLabel(mapped, name, l.Value)
+			}
+		}
+		labels = mapped
+	}
+	return labels, true
+}
+
+func labelValue(labels []impstats.Label, name string) string {
+	for _, l := range labels {
+		if l.Name == name {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+func setLabel(labels []impstats.Label, name, value string) []impstats.Label {
+	res := make([]impstats.Label, 0, len(labels)+1)
+	found := false
+	for _, l := range labels {
+		if l.Name == name {
+			l.Value = value
+			found = true
+		}
+		res = append(res, l)
+	}
+	if !found {
+		res = append(res, impstats.Label{Name: name, Value: value})
+	}
+	return res
+}
+
+func deleteLabel(labels []impstats.Label, name string) []impstats.Label {
+	res := make([]impstats.Label, 0, len(labels))
+	for _, l := range labels {
+		if l.Name != name {
+			res = append(res, l)
+		}
+	}
+	return res
+}
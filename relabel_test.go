@@ -0,0 +1,257 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestRelabel(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  []impstats.Label
+		cfgs   []*relabelConfig
+		output []impstats.Label
+	}{
+		{
+			name:  "replace with capture group",
+			input: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "b", Value: "bar"}, {Name: "c", Value: "baz"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr("f(.*)"),
+				TargetLabel:  "d",
+				Replacement:  strPtr("ch${1}-ch${1}"),
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "b", Value: "bar"}, {Name: "c", Value: "baz"}, {Name: "d", Value: "choo-choo"}},
+		},
+		{
+			name:  "replace joins source labels",
+			input: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "b", Value: "bar"}, {Name: "c", Value: "baz"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a", "b"},
+				Regex:        strPtr("f(.*);(.*)r"),
+				TargetLabel:  "a",
+				Replacement:  strPtr("b${1}${2}m"),
+			}, {
+				SourceLabels: []string{"c", "a"},
+				Regex:        strPtr("(b).*b(.*)ba(.*)"),
+				TargetLabel:  "d",
+				Replacement:  strPtr("$1$2$2$3"),
+			}},
+			output: []impstats.Label{{Name: "a", Value: "boobam"}, {Name: "b", Value: "bar"}, {Name: "c", Value: "baz"}, {Name: "d", Value: "boooom"}},
+		},
+		{
+			name:  "replace regex is anchored",
+			input: []impstats.Label{{Name: "a", Value: "foo"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr("o"),
+				TargetLabel:  "b",
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}},
+		},
+		{
+			name:  "replace with empty value deletes label",
+			input: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "b", Value: "bar"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr("(f).*"),
+				TargetLabel:  "b",
+				Replacement:  strPtr(""),
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}},
+		},
+		{
+			name:  "replace with missing source label",
+			input: []impstats.Label{{Name: "a", Value: "foo"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"b"},
+				Regex:        strPtr("(.*)"),
+				TargetLabel:  "c",
+				Replacement:  strPtr("x$1"),
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "c", Value: "x"}},
+		},
+		{
+			name:  "replace with templated target label",
+			input: []impstats.Label{{Name: "a", Value: "some-name-value"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr("some-([^-]+)-([^,]+)"),
+				TargetLabel:  "${1}",
+				Replacement:  strPtr("${2}"),
+			}},
+			output: []impstats.Label{{Name: "a", Value: "some-name-value"}, {Name: "name", Value: "value"}},
+		},
+		{
+			name:  "replace with invalid target label",
+			input: []impstats.Label{{Name: "a", Value: "some-name-0"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr("some-([^-]+)-([^,]+)"),
+				TargetLabel:  "${3}",
+				Replacement:  strPtr("${1}"),
+			}},
+			output: []impstats.Label{{Name: "a", Value: "some-name-0"}},
+		},
+		{
+			name:  "drop",
+			input: []impstats.Label{{Name: "a", Value: "foo"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr(".*o.*"),
+				Action:       relabelDrop,
+			}},
+			output: nil,
+		},
+		{
+			name:  "drop not matching",
+			input: []impstats.Label{{Name: "a", Value: "foo"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr("f|o"),
+				Action:       relabelDrop,
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}},
+		},
+		{
+			name:  "keep",
+			input: []impstats.Label{{Name: "a", Value: "foo"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr("f.*"),
+				Action:       relabelKeep,
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}},
+		},
+		{
+			name:  "keep not matching",
+			input: []impstats.Label{{Name: "a", Value: "foo"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				Regex:        strPtr("no-match"),
+				Action:       relabelKeep,
+			}},
+			output: nil,
+		},
+		{
+			name:  "keep on missing label",
+			input: []impstats.Label{{Name: "a", Value: "foo"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"b"},
+				Regex:        strPtr(""),
+				Action:       relabelKeep,
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}},
+		},
+		{
+			name:  "labeldrop",
+			input: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "b", Value: "bar"}, {Name: "c", Value: "baz"}},
+			cfgs: []*relabelConfig{{
+				Regex:  strPtr("(b|c)"),
+				Action: relabelLabelDrop,
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}},
+		},
+		{
+			name:  "labelmap",
+			input: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "b", Value: "bar"}, {Name: "c", Value: "baz"}},
+			cfgs: []*relabelConfig{{
+				Regex:       strPtr("(b|c)"),
+				Replacement: strPtr("${1}_new"),
+				Action:      relabelLabelMap,
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "b", Value: "bar"}, {Name: "c", Value: "baz"}, {Name: "b_new", Value: "bar"}, {Name: "c_new", Value: "baz"}},
+		},
+		{
+			name:  "temporary labels are removed",
+			input: []impstats.Label{{Name: "a", Value: "foo"}},
+			cfgs: []*relabelConfig{{
+				SourceLabels: []string{"a"},
+				TargetLabel:  "__tmp",
+			}, {
+				SourceLabels: []string{"__tmp"},
+				TargetLabel:  "b",
+			}},
+			output: []impstats.Label{{Name: "a", Value: "foo"}, {Name: "b", Value: "foo"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, c := range tc.cfgs {
+			if err := c.validate(); err != nil {
+				t.Fatalf("%s: expected relabel config to be valid, got: %v", tc.name, err)
+			}
+		}
+		p := &impstats.Point{Name: "test", Type: impstats.Counter}
+		p.SetLabels(tc.input)
+
+		r := relabel(p, tc.cfgs)
+		if tc.output == nil {
+			if r != nil {
+				t.Errorf("%s: expected point to be dropped, got %v", tc.name, r.Labels())
+			}
+			continue
+		}
+		if r == nil {
+			t.Errorf("%s: expected point not to be dropped", tc.name)
+			continue
+		}
+		if want, got := tc.output, r.Labels(); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: want %v, got %v", tc.name, want, got)
+		}
+	}
+}
+
+func TestRelabelMetricName(t *testing.T) {
+	cfgs := []*relabelConfig{{
+		SourceLabels: []string{metricNameLabel, "action"},
+		Regex:        strPtr("action_processed;action (\\d+)"),
+		Action:       relabelDrop,
+	}, {
+		SourceLabels: []string{metricNameLabel},
+		Regex:        strPtr("action_(.*)"),
+		TargetLabel:  metricNameLabel,
+		Replacement:  strPtr("output_$1"),
+	}}
+	for _, c := range cfgs {
+		if err := c.validate(); err != nil {
+			t.Fatalf("expected relabel config to be valid, got: %v", err)
+		}
+	}
+
+	p := &impstats.Point{Name: "action_processed", LabelName: "action", LabelValue: "action 3"}
+	if r := relabel(p, cfgs); r != nil {
+		t.Errorf("expected point to be dropped, got %v", r.Key())
+	}
+
+	p = &impstats.Point{Name: "action_failed", LabelName: "action", LabelValue: "action 3"}
+	r := relabel(p, cfgs)
+	if r == nil {
+		t.Fatal("expected point not to be dropped")
+	}
+	if want, got := `output_failed{action="action 3"}`, r.Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+	if want, got := "action_failed", p.Name; want != got {
+		t.Errorf("expected original point to be unchanged, want '%s', got '%s'", want, got)
+	}
+}
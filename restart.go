@@ -0,0 +1,36 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sync"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+// restartDetector detects restarts of rsyslog by the user time of resource
+// objects going backwards.
+type restartDetector struct {
+	lock  sync.Mutex
+	utime map[string]int64
+}
+
+func (d *restartDetector) restarted(r *impstats.Resource) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	last, seen := d.utime[r.Name]
+	d.utime[r.Name] = r.Utime
+	return seen && r.Utime < last
+}
@@ -0,0 +1,34 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+func TestRestartDetector(t *testing.T) {
+	d := restartDetector{utime: make(map[string]int64)}
+
+	if d.restarted(&impstats.Resource{Name: "resource-usage", Utime: 100}) {
+		t.Error("expected first report not to be a restart")
+	}
+	if d.restarted(&impstats.Resource{Name: "resource-usage", Utime: 200}) {
+		t.Error("expected increasing utime not to be a restart")
+	}
+	if !d.restarted(&impstats.Resource{Name: "resource-usage", Utime: 50}) {
+		t.Error("expected decreasing utime to be a restart")
+	}
+}
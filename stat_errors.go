@@ -0,0 +1,103 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+// Reasons for failing to handle a stats line.
+const (
+	reasonSplit       = "split"
+	reasonUnknownType = "unknown_type"
+	reasonDecode      = "decode"
+	reasonInvalidName = "invalid_name"
+	reasonOther       = "other"
+)
+
+var statLineErrorReasons = []string{reasonSplit, reasonUnknownType, reasonDecode, reasonInvalidName, reasonOther}
+
+// statLineError is an error handling a stats line, with the reason and the
+// origin of the line, if known.
+type statLineError struct {
+	reason string
+	origin string
+	err    error
+}
+
+func newStatLineError(reason, origin string, err error) *statLineError {
+	return &statLineError{reason: reason, origin: origin, err: err}
+}
+
+func (e *statLineError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statLineError) Unwrap() error {
+	return e.err
+}
+
+// statLineErrors counts errors handling stats lines by reason and origin.
+type statLineErrors struct {
+	lock   sync.Mutex
+	counts map[[2]string]int64
+}
+
+func newStatLineErrors() *statLineErrors {
+	return &statLineErrors{
+		counts: make(map[[2]string]int64),
+	}
+}
+
+// count records err and stores the updated point of its reason and origin
+// with store. The point is stored with the lock held, so that concurrent
+// counts are stored in the order they were counted.
+func (c *statLineErrors) count(err error, store func(*impstats.Point) error) error {
+	reason, origin := reasonOther, ""
+	var se *statLineError
+	if errors.As(err, &se) {
+		reason, origin = se.reason, se.origin
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := [2]string{reason, origin}
+	c.counts[key]++
+	return store(statLineErrorPoint(reason, origin, c.counts[key]))
+}
+
+// initialPoints returns points of zero errors for all reasons, so that the
+// family exists before the first error.
+func (c *statLineErrors) initialPoints() []*impstats.Point {
+	points := make([]*impstats.Point, 0, len(statLineErrorReasons))
+	for _, reason := range statLineErrorReasons {
+		points = append(points, statLineErrorPoint(reason, "", 0))
+	}
+	return points
+}
+
+func statLineErrorPoint(reason, origin string, value int64) *impstats.Point {
+	return &impstats.Point{
+		Name:        "stats_line_errors",
+		Type:        impstats.Counter,
+		Value:       value,
+		Description: "Counts errors during stats line handling",
+		LabelName:   "reason",
+		LabelValue:  reason,
+		ExtraLabels: []impstats.Label{{Name: "origin", Value: origin}},
+	}
+}
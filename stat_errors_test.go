@@ -0,0 +1,139 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+func TestStatLineErrorReasons(t *testing.T) {
+	testCases := []struct {
+		line   string
+		reason string
+		origin string
+	}{
+		{`broken`, reasonSplit, ""},
+		{`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"x","origin":"mystery","a":1}`, reasonUnknownType, "mystery"},
+		{`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"main Q","origin":"core.queue","enqueued":"many"}`, reasonDecode, "core.queue"},
+		{`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"origin":"core.queue","enqueued":1}`, reasonInvalidName, "core.queue"},
+	}
+
+	re := newRsyslogExporter()
+	for _, tc := range testCases {
+		err := re.handleStatLine([]byte(tc.line))
+		var se *statLineError
+		if !errors.As(err, &se) {
+			t.Errorf("%s: expected stats line error, got: %v", tc.line, err)
+			continue
+		}
+		if se.reason != tc.reason || se.origin != tc.origin {
+			t.Errorf("%s: want (%s, %s), got (%s, %s)", tc.line, tc.reason, tc.origin, se.reason, se.origin)
+		}
+	}
+}
+
+func TestStatLineErrorInvalidNameWithoutSideEffects(t *testing.T) {
+	re := newRsyslogExporter()
+	action := `2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"a","origin":"core.action","processed":10,"failed":0,"suspended":0,"suspended.duration":0,"resumed":0}`
+	if err := re.handleStatLine([]byte(action)); err != nil {
+		t.Fatalf("expected handling line not to fail, got: %v", err)
+	}
+
+	// Nameless resource lines with user time going backwards must not be
+	// taken for a restart of rsyslog.
+	for _, utime := range []int{100, 50} {
+		line := fmt.Sprintf(`2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"origin":"impstats","utime":%d,"stime":1,"maxrss":1,"minflt":1,"majflt":0,"inblock":0,"oublock":0,"nvcsw":1,"nivcsw":1}`, utime)
+		err := re.handleStatLine([]byte(line))
+		var se *statLineError
+		if !errors.As(err, &se) || se.reason != reasonInvalidName {
+			t.Fatalf("expected invalid name error, got: %v", err)
+		}
+	}
+
+	p, err := re.get(`action_processed{action="a",action_index="",builtin="",module=""}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Created.IsZero() {
+		t.Errorf("expected nameless lines not to mark a restart, got created '%v'", p.Created)
+	}
+}
+
+func TestStatLineErrorsCount(t *testing.T) {
+	var p *impstats.Point
+	store := func(stored *impstats.Point) error {
+		p = stored
+		return nil
+	}
+
+	c := newStatLineErrors()
+	c.count(newStatLineError(reasonDecode, "core.queue", errors.New("a")), store)
+	c.count(newStatLineError(reasonDecode, "core.queue", errors.New("b")), store)
+	if want, got := `stats_line_errors{origin="core.queue",reason="decode"}`, p.Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+	if want, got := int64(2), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+
+	c.count(errors.New("c"), store)
+	if want, got := `stats_line_errors{origin="",reason="other"}`, p.Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+}
+
+// TestStatLineErrorsConcurrentCollect is meant to be run with -race.
+func TestStatLineErrorsConcurrentCollect(t *testing.T) {
+	re := newRsyslogExporter()
+	for _, p := range re.lineErrors.initialPoints() {
+		re.set(p)
+	}
+
+	// Errors counted concurrently have to be stored in order, or the stored
+	// count falls behind.
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				re.handleLine([]byte(`broken`), true)
+			}
+		}()
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(re)
+	for i := 0; i < 100; i++ {
+		if _, err := reg.Gather(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	p, err := re.get(`stats_line_errors{origin="",reason="split"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(1000), p.Value; want != got {
+		t.Errorf("want '%d', got '%d'", want, got)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+// envelopeFields are present in all stats lines and used by the exporter
+// whether or not the decoded type maps them.
+var envelopeFields = map[string]bool{"name": true, "origin": true}
+
+// unmappedFields detects fields of stats lines that are not mapped by the
+// type the line is decoded into, e.g. counters added by new versions of
+// rsyslog.
+type unmappedFields struct {
+	lock   sync.Mutex
+	fields map[reflect.Type]map[string]bool
+	seen   map[string]bool
+}
+
+func newUnmappedFields() *unmappedFields {
+	return &unmappedFields{
+		fields: make(map[reflect.Type]map[string]bool),
+		seen:   make(map[string]bool),
+	}
+}
+
+// check returns info points for the fields of buf which are not mapped by
+// decoded, logging each of them when it is first seen.
+func (u *unmappedFields) check(origin string, buf []byte, decoded interface{}) []*impstats.Point {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &values); err != nil {
+		return nil
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	mapped := u.mappedFields(reflect.TypeOf(decoded))
+	unmapped := make([]string, 0)
+	for field := range values {
+		if !mapped[field] && !envelopeFields[field] {
+			unmapped = append(unmapped, field)
+		}
+	}
+	sort.Strings(unmapped)
+
+	points := make([]*impstats.Point, 0, len(unmapped))
+	for _, field := range unmapped {
+		p := &impstats.Point{
+			Name:        "exporter_unmapped_field_info",
+			Type:        impstats.Gauge,
+			Value:       1,
+			Description: "fields of stats lines not exported by the exporter",
+			LabelName:   "origin",
+			LabelValue:  origin,
+			ExtraLabels: []impstats.Label{{Name: "field", Value: field}},
+		}
+		if key := p.Key(); !u.seen[key] {
+			u.seen[key] = true
+			log.Printf("stats lines of origin %q have unmapped field %q", origin, field)
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// mappedFields returns the JSON field names of a struct type. It has to be
+// called with the lock held.
+func (u *unmappedFields) mappedFields(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if fields, ok := u.fields[t]; ok {
+		return fields
+	}
+
+	fields := make(map[string]bool)
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fields[name] = true
+		}
+	}
+	u.fields[t] = fields
+	return fields
+}
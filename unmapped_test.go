@@ -0,0 +1,62 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/rsyslog_exporter/impstats"
+)
+
+func TestUnmappedFields(t *testing.T) {
+	u := newUnmappedFields()
+	buf := []byte(`{"name":"imuxsock","origin":"imuxsock","submitted":10,"ratelimit.discarded":2,"ratelimit.numratelimiters":1}`)
+	i, err := impstats.NewInputFromJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	points := u.check("imuxsock", buf, i)
+	if want, got := 2, len(points); want != got {
+		t.Fatalf("want %d points, got %d", want, got)
+	}
+	if want, got := `exporter_unmapped_field_info{field="ratelimit.discarded",origin="imuxsock"}`, points[0].Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+	if want, got := `exporter_unmapped_field_info{field="ratelimit.numratelimiters",origin="imuxsock"}`, points[1].Key(); want != got {
+		t.Errorf("want '%s', got '%s'", want, got)
+	}
+
+	buf = []byte(`{"name":"main Q","origin":"core.queue","size":10,"enqueued":20,"full":30,"discarded.full":40,"discarded.nf":50,"maxqsize":60}`)
+	q, err := impstats.NewQueueFromJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if points := u.check("core.queue", buf, q); len(points) != 0 {
+		t.Errorf("expected all queue fields to be mapped, got %d unmapped", len(points))
+	}
+}
+
+func TestHandleLineWithUnmappedFields(t *testing.T) {
+	re := newRsyslogExporter()
+	re.unmappedFields = newUnmappedFields()
+
+	line := `2017-08-30T08:10:04.786350+00:00 some-node.example.org rsyslogd-pstats: {"name":"imptcp(*/514/IPv4)","origin":"imptcp","submitted":5,"sessions.opened":1}`
+	if err := re.handleStatLine([]byte(line)); err != nil {
+		t.Fatalf("expected handling line not to fail, got: %v", err)
+	}
+	if _, err := re.get(`exporter_unmapped_field_info{field="sessions.opened",origin="imptcp"}`); err != nil {
+		t.Errorf("expected unmapped field to be reported, got: %v", err)
+	}
+}